@@ -22,6 +22,7 @@ interface ParsedExpense {
   category_id: number;
   unit: number;
   per_unit_cost: number;
+  merchant?: string;
 }
 
 export default function AddExpensePage() {
@@ -173,7 +174,8 @@ export default function AddExpensePage() {
           name: exp.description || 'Expense',
           category_id: mapCategoryNameToId(exp.category),
           unit: 1,
-          per_unit_cost: exp.amount
+          per_unit_cost: exp.amount,
+          merchant: exp.merchant || undefined
         }));
 
         setExtractedExpenses(parsed);
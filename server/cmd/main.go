@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -12,10 +16,25 @@ import (
 	"github.com/parvejmia9/minflow/server/internal/handlers"
 	"github.com/parvejmia9/minflow/server/internal/models"
 	"github.com/parvejmia9/minflow/server/internal/routes"
+	"github.com/parvejmia9/minflow/server/internal/services/account"
 	"github.com/parvejmia9/minflow/server/internal/services/auth"
+	"github.com/parvejmia9/minflow/server/internal/services/budget"
 	"github.com/parvejmia9/minflow/server/internal/services/category"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
 	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"github.com/parvejmia9/minflow/server/internal/services/goal"
+	"github.com/parvejmia9/minflow/server/internal/services/idempotency"
+	"github.com/parvejmia9/minflow/server/internal/services/importer"
+	"github.com/parvejmia9/minflow/server/internal/services/income"
+	"github.com/parvejmia9/minflow/server/internal/services/merchant"
+	"github.com/parvejmia9/minflow/server/internal/services/receipt"
+	"github.com/parvejmia9/minflow/server/internal/services/recurring"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+	"github.com/parvejmia9/minflow/server/internal/services/split"
+	"github.com/parvejmia9/minflow/server/internal/services/tag"
+	"github.com/parvejmia9/minflow/server/internal/services/trash"
 	"github.com/parvejmia9/minflow/server/internal/services/user"
+	"github.com/parvejmia9/minflow/server/internal/storage"
 )
 
 func main() {
@@ -30,11 +49,38 @@ func main() {
 	db.ConnectDB()
 
 	// Auto migrate database models
-	err := db.DB.AutoMigrate(&models.User{}, &models.Category{}, &models.Expense{})
+	err := db.DB.AutoMigrate(
+		&models.User{},
+		&models.Category{},
+		&models.Expense{},
+		&models.RecurringExpense{},
+		&models.ExchangeRate{},
+		&models.Receipt{},
+		&models.Tag{},
+		&models.ExpenseSplit{},
+		&models.Settlement{},
+		&models.Contact{},
+		&models.ExpenseRevision{},
+		&models.Merchant{},
+		&models.MerchantAlias{},
+		&models.Account{},
+		&models.Transfer{},
+		&models.Income{},
+		&models.Budget{},
+		&models.BudgetAlert{},
+		&models.Goal{},
+		&models.GoalContribution{},
+		&models.IdempotencyKey{},
+	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Apply data migrations that AutoMigrate can't express
+	if err := db.RunMigrations(db.DB); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
 	// Seed default categories
 	tempCategoryService := category.NewService(db.DB)
 	if err := tempCategoryService.SeedDefaultCategories(); err != nil {
@@ -43,6 +89,16 @@ func main() {
 		log.Println("Default categories seeded successfully")
 	}
 
+	// Load exchange rates from a local file if configured
+	if ratesFile := os.Getenv("EXCHANGE_RATES_FILE"); ratesFile != "" {
+		loaded, err := currency.NewService(db.DB).LoadFile(ratesFile)
+		if err != nil {
+			log.Println("Warning: Failed to load exchange rates:", err)
+		} else {
+			log.Printf("Loaded %d exchange rates from %s", loaded, ratesFile)
+		}
+	}
+
 	// Get JWT secret from environment or use default (change in production!)
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -50,11 +106,69 @@ func main() {
 		log.Println("Warning: Using default JWT secret. Set JWT_SECRET environment variable in production!")
 	}
 
+	// Receipt storage backend (local filesystem or S3-compatible)
+	receiptStorage, err := newReceiptStorage()
+	if err != nil {
+		log.Fatal("Failed to set up receipt storage:", err)
+	}
+	receiptMaxSize := receipt.DefaultMaxSize
+	if value := os.Getenv("RECEIPT_MAX_SIZE_MB"); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			receiptMaxSize = parsed << 20
+		} else {
+			log.Println("Warning: Invalid RECEIPT_MAX_SIZE_MB, using default of 10")
+		}
+	}
+
+	// Expenses this many days apart can still be flagged as duplicates
+	duplicateWindowDays := 3
+	if value := os.Getenv("DUPLICATE_WINDOW_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			duplicateWindowDays = parsed
+		} else {
+			log.Println("Warning: Invalid DUPLICATE_WINDOW_DAYS, using default of 3")
+		}
+	}
+
+	// Deleted expenses and categories are purged after this many days (0 keeps them)
+	trashRetentionDays := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			trashRetentionDays = parsed
+		} else {
+			log.Println("Warning: Invalid TRASH_RETENTION_DAYS, using default of 30")
+		}
+	}
+
+	// Responses to requests with an Idempotency-Key are replayed for this long
+	idempotencyTTL := idempotency.DefaultTTL
+	if value := os.Getenv("IDEMPOTENCY_KEY_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			idempotencyTTL = parsed
+		} else {
+			log.Println("Warning: Invalid IDEMPOTENCY_KEY_TTL, using default of 24h")
+		}
+	}
+
 	// Initialize services with dependency injection
 	authService := auth.NewService(db.DB, jwtSecret)
 	categoryService := category.NewService(db.DB)
-	expenseService := expense.NewService(db.DB)
+	expenseService := expense.NewService(db.DB, time.Duration(duplicateWindowDays)*24*time.Hour)
 	userService := user.NewService(db.DB)
+	importService := importer.NewService(db.DB, expenseService)
+	recurringService := recurring.NewService(db.DB)
+	currencyService := currency.NewService(db.DB)
+	receiptService := receipt.NewService(db.DB, receiptStorage, receiptMaxSize)
+	tagService := tag.NewService(db.DB)
+	splitService := split.NewService(db.DB)
+	revisionService := revision.NewService(db.DB)
+	merchantService := merchant.NewService(db.DB)
+	accountService := account.NewService(db.DB)
+	incomeService := income.NewService(db.DB)
+	budgetService := budget.NewService(db.DB, expenseService)
+	goalService := goal.NewService(db.DB, expenseService, incomeService)
+	idempotencyService := idempotency.NewService(db.DB, idempotencyTTL)
+	trashService := trash.NewService(db.DB, receiptService, time.Duration(trashRetentionDays)*24*time.Hour)
 
 	// Initialize handlers with service dependencies
 	authHandler := handlers.NewAuthHandler(authService)
@@ -62,9 +176,46 @@ func main() {
 	expenseHandler := handlers.NewExpenseHandler(expenseService)
 	userHandler := handlers.NewUserHandler(userService)
 	aiExpenseHandler := handlers.NewAIExpenseHandler()
+	importHandler := handlers.NewImportHandler(importService)
+	recurringHandler := handlers.NewRecurringExpenseHandler(recurringService)
+	currencyHandler := handlers.NewCurrencyHandler(currencyService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	splitHandler := handlers.NewSplitHandler(splitService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	revisionHandler := handlers.NewRevisionHandler(revisionService)
+	merchantHandler := handlers.NewMerchantHandler(merchantService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	incomeHandler := handlers.NewIncomeHandler(incomeService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	goalHandler := handlers.NewGoalHandler(goalService)
+
+	// Check budget alerts whenever expenses are added, changed or restored
+	expenseService.OnChange(budgetService.CheckAlerts)
+	recurringService.OnCreate(budgetService.CheckAlerts)
+	trashService.OnRestore(budgetService.CheckAlerts)
+
+	// Start the recurring expense scheduler (posts due occurrences)
+	schedulerInterval := time.Hour
+	if value := os.Getenv("RECURRING_SCHEDULER_INTERVAL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			schedulerInterval = parsed
+		} else {
+			log.Println("Warning: Invalid RECURRING_SCHEDULER_INTERVAL, using default of 1h")
+		}
+	}
+	go recurringService.StartScheduler(context.Background(), schedulerInterval)
+
+	// Purge expired trash in the background
+	go trashService.StartPurger(context.Background(), time.Hour)
+
+	// Purge expired idempotency keys in the background
+	go idempotencyService.StartPurger(context.Background(), time.Hour)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		// Leave room for receipt uploads plus multipart overhead
+		BodyLimit: int(max(receiptMaxSize, 4<<20) + 1<<20),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -87,13 +238,14 @@ func main() {
 	}
 
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: allowedOrigins,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+		AllowOrigins:  allowedOrigins,
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, Idempotency-Key, If-Match, If-None-Match",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		ExposeHeaders: "ETag, Idempotent-Replayed",
 	}))
 
 	// Setup routes with handler dependencies
-	routes.SetupRoutes(app, authService, authHandler, categoryHandler, expenseHandler, userHandler, aiExpenseHandler)
+	routes.SetupRoutes(app, authService, idempotencyService, authHandler, categoryHandler, expenseHandler, userHandler, aiExpenseHandler, importHandler, recurringHandler, currencyHandler, receiptHandler, tagHandler, splitHandler, trashHandler, revisionHandler, merchantHandler, accountHandler, incomeHandler, budgetHandler, goalHandler)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -109,3 +261,26 @@ func main() {
 	log.Printf("Server starting on :%s (Environment: %s)", port, env)
 	log.Fatal(app.Listen(":" + port))
 }
+
+// newReceiptStorage creates the receipt storage backend selected by
+// RECEIPT_STORAGE ("local", the default, or "s3")
+func newReceiptStorage() (storage.Storage, error) {
+	switch os.Getenv("RECEIPT_STORAGE") {
+	case "", "local":
+		dir := os.Getenv("RECEIPT_STORAGE_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		return storage.NewLocal(dir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") != "false",
+		})
+	}
+	return nil, errors.New("RECEIPT_STORAGE must be local or s3")
+}
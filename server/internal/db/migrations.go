@@ -0,0 +1,167 @@
+package db
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a one-off schema or data change that AutoMigrate can't express
+type Migration struct {
+	ID string
+	Up func(tx *gorm.DB) error
+}
+
+// schemaMigration records an applied migration
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations run in order, each at most once. Never edit or reorder an
+// entry that has shipped; append a new one instead.
+var migrations = []Migration{
+	{
+		// Totals used to be computed as unit * per_unit_cost in float64 and
+		// could be off by a cent. per_unit_cost was always stored exactly as
+		// decimal(10,2), so recompute totals from it with exact numeric
+		// arithmetic, rounding half away from zero like money.Amount does.
+		ID: "20261016_recompute_expense_totals_exactly",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(`UPDATE expenses
+				SET total = ROUND(unit::numeric * per_unit_cost, 2)
+				WHERE total IS DISTINCT FROM ROUND(unit::numeric * per_unit_cost, 2)`).Error
+		},
+	},
+	{
+		// Full-text search over expenses. The vector includes the merchant
+		// and category names, which a generated column can't reference, so
+		// it is kept up to date by triggers instead: on every write to an
+		// expense, and on every expense of a renamed category or merchant.
+		ID: "20261016_add_expense_search_vector",
+		Up: func(tx *gorm.DB) error {
+			statements := []string{
+				`ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+				`CREATE OR REPLACE FUNCTION expenses_search_vector_update() RETURNS trigger AS $$
+				BEGIN
+					NEW.search_vector :=
+						setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+						setweight(to_tsvector('english', COALESCE((SELECT name FROM merchants WHERE id = NEW.merchant_id), '') || ' ' || COALESCE(NEW.raw_merchant, '')), 'B') ||
+						setweight(to_tsvector('english', COALESCE((SELECT name FROM categories WHERE id = NEW.category_id), '')), 'C') ||
+						setweight(to_tsvector('english', COALESCE(NEW.notes, '')), 'D');
+					RETURN NEW;
+				END
+				$$ LANGUAGE plpgsql`,
+				`DROP TRIGGER IF EXISTS expenses_search_vector ON expenses`,
+				`CREATE TRIGGER expenses_search_vector BEFORE INSERT OR UPDATE ON expenses
+				FOR EACH ROW EXECUTE FUNCTION expenses_search_vector_update()`,
+				`CREATE OR REPLACE FUNCTION expenses_search_vector_refresh_category() RETURNS trigger AS $$
+				BEGIN
+					UPDATE expenses SET category_id = category_id WHERE category_id = NEW.id;
+					RETURN NULL;
+				END
+				$$ LANGUAGE plpgsql`,
+				`DROP TRIGGER IF EXISTS categories_search_vector ON categories`,
+				`CREATE TRIGGER categories_search_vector AFTER UPDATE OF name ON categories
+				FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+				EXECUTE FUNCTION expenses_search_vector_refresh_category()`,
+				`CREATE OR REPLACE FUNCTION expenses_search_vector_refresh_merchant() RETURNS trigger AS $$
+				BEGIN
+					UPDATE expenses SET merchant_id = merchant_id WHERE merchant_id = NEW.id;
+					RETURN NULL;
+				END
+				$$ LANGUAGE plpgsql`,
+				`DROP TRIGGER IF EXISTS merchants_search_vector ON merchants`,
+				`CREATE TRIGGER merchants_search_vector AFTER UPDATE OF name ON merchants
+				FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+				EXECUTE FUNCTION expenses_search_vector_refresh_merchant()`,
+				// Fill the vector of existing expenses
+				`UPDATE expenses SET name = name`,
+				`CREATE INDEX IF NOT EXISTS idx_expenses_search_vector ON expenses USING GIN (search_vector)`,
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// Amounts on expenses, recurring expenses and splits were
+		// decimal(10,2) while accounts, transfers, incomes, budgets and goals
+		// use decimal(12,2). Widen them so every amount column has the same
+		// range; AutoMigrate doesn't change the precision of existing columns.
+		ID: "20261016_widen_amount_columns",
+		Up: func(tx *gorm.DB) error {
+			statements := []string{
+				`ALTER TABLE expenses ALTER COLUMN per_unit_cost TYPE decimal(12,2), ALTER COLUMN total TYPE decimal(12,2)`,
+				`ALTER TABLE recurring_expenses ALTER COLUMN per_unit_cost TYPE decimal(12,2)`,
+				`ALTER TABLE expense_splits ALTER COLUMN amount TYPE decimal(12,2)`,
+				`ALTER TABLE settlements ALTER COLUMN amount TYPE decimal(12,2)`,
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// Split weights were floats, so percentage and exact splits could
+		// drift by a cent when rebalanced. They are now integer shares: 1
+		// for equal splits, hundredths of a percent, or minor units.
+		ID: "20261016_split_shares",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn("expense_splits", "weight") {
+				return nil
+			}
+			statements := []string{
+				`UPDATE expense_splits SET shares = CASE method WHEN 'equal' THEN 1 ELSE ROUND(weight * 100) END WHERE shares = 0`,
+				`ALTER TABLE expense_splits DROP COLUMN weight`,
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// RunMigrations applies pending migrations. Call it after AutoMigrate.
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		var count int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", migration.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{ID: migration.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied migration %s", migration.ID)
+	}
+
+	return nil
+}
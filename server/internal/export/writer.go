@@ -0,0 +1,158 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Cell is a single exported value. Numeric cells hold a decimal literal and
+// are written as numbers where the format supports it.
+type Cell struct {
+	Text    string
+	Numeric bool
+}
+
+// Text returns a text cell
+func Text(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Number returns a numeric cell
+func Number(f float64) Cell {
+	return Cell{Text: strconv.FormatFloat(f, 'f', -1, 64), Numeric: true}
+}
+
+// Decimal returns a numeric cell from an exact decimal literal such as "12.50"
+func Decimal(literal string) Cell {
+	return Cell{Text: literal, Numeric: true}
+}
+
+func (c Cell) String() string {
+	return c.Text
+}
+
+// RowWriter writes tabular rows one at a time so exports never have to be
+// held in memory
+type RowWriter interface {
+	WriteRow(cells []Cell) error
+	Close() error
+}
+
+// Format describes an export file format
+type Format struct {
+	ContentType string
+	Extension   string
+	New         func(w io.Writer, header []string) (RowWriter, error)
+}
+
+// Formats lists the supported export formats by name
+var Formats = map[string]Format{
+	"csv": {
+		ContentType: "text/csv; charset=utf-8",
+		Extension:   "csv",
+		New:         NewCSVWriter,
+	},
+	"json": {
+		ContentType: "application/json",
+		Extension:   "json",
+		New:         NewJSONWriter,
+	},
+	"xlsx": {
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Extension:   "xlsx",
+		New:         NewXLSXWriter,
+	},
+}
+
+// csvWriter writes rows as CSV with a header line
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter creates a CSV row writer
+func NewCSVWriter(w io.Writer, header []string) (RowWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) WriteRow(cells []Cell) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cell.String()
+		if !cell.Numeric {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	return c.w.Write(record)
+}
+
+// escapeFormula prefixes text that a spreadsheet would run as a formula
+// with a quote, so opening an export never evaluates user input
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes rows as a JSON array of objects keyed by the header
+type jsonWriter struct {
+	w      io.Writer
+	header []string
+	count  int
+}
+
+// NewJSONWriter creates a JSON row writer
+func NewJSONWriter(w io.Writer, header []string) (RowWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w, header: header}, nil
+}
+
+func (j *jsonWriter) WriteRow(cells []Cell) error {
+	if len(cells) != len(j.header) {
+		return errors.New("row length does not match header")
+	}
+
+	object := make(map[string]interface{}, len(cells))
+	for i, cell := range cells {
+		if cell.Numeric {
+			object[j.header[i]] = json.RawMessage(cell.Text)
+		} else {
+			object[j.header[i]] = cell.Text
+		}
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.count++
+
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	_, err := io.WriteString(j.w, "]")
+	return err
+}
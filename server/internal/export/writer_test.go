@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Coffee", "Coffee"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1+2", "'+1+2"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf, []string{"name", "total"})
+	if err != nil {
+		t.Fatalf("NewCSVWriter() error = %v", err)
+	}
+	rows := [][]Cell{
+		{Text("Lunch, with team"), Decimal("12.50")},
+		{Text("=1+1"), Decimal("-3.00")},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := "name,total\n\"Lunch, with team\",12.50\n'=1+1,-3.00\n"
+	if buf.String() != want {
+		t.Errorf("CSV output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewJSONWriter(&buf, []string{"id", "name"})
+	if err != nil {
+		t.Fatalf("NewJSONWriter() error = %v", err)
+	}
+	if err := w.WriteRow([]Cell{Number(1), Text("=Tea")}); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := w.WriteRow([]Cell{Number(2), Text(`"quoted"`)}); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := w.WriteRow([]Cell{Number(3)}); err == nil {
+		t.Error("WriteRow() with a short row should fail")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%s)", err, buf.String())
+	}
+	if len(got) != 2 || got[0]["id"] != float64(1) || got[0]["name"] != "=Tea" || got[1]["name"] != `"quoted"` {
+		t.Errorf("JSON output = %v", got)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, []string{"name", "total"})
+	if err != nil {
+		t.Fatalf("NewXLSXWriter() error = %v", err)
+	}
+	if err := w.WriteRow([]Cell{Text("Fish & <Chips>"), Decimal("7.25")}); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip file: %v", err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open sheet: %v", err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		sheet = string(data)
+	}
+
+	for _, want := range []string{
+		`<t xml:space="preserve">name</t>`,
+		`<t xml:space="preserve">Fish &amp; &lt;Chips&gt;</t>`,
+		`<c t="n"><v>7.25</v></c>`,
+		`</sheetData></worksheet>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet is missing %s:\n%s", want, sheet)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/account"
+)
+
+// AccountHandler handles HTTP requests for accounts and transfers
+type AccountHandler struct {
+	accountService *account.Service
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *account.Service) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// GetAll handles GET /accounts
+func (h *AccountHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	accounts, err := h.accountService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch accounts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    accounts,
+		"count":   len(accounts),
+	})
+}
+
+// GetByID handles GET /accounts/:id
+func (h *AccountHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid account ID",
+		})
+	}
+
+	found, err := h.accountService.GetByID(uint(id), userID)
+	if err != nil {
+		return accountError(c, err, "Failed to fetch account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    found,
+	})
+}
+
+// GetHistory handles GET /accounts/:id/history. The range defaults to the
+// last 30 days.
+func (h *AccountHandler) GetHistory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid account ID",
+		})
+	}
+
+	endDate := time.Now()
+	if value := c.Query("end_date"); value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid end_date format (use YYYY-MM-DD)",
+			})
+		}
+	}
+	startDate := endDate.AddDate(0, 0, -29)
+	if value := c.Query("start_date"); value != "" {
+		if startDate, err = time.Parse("2006-01-02", value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid start_date format (use YYYY-MM-DD)",
+			})
+		}
+	}
+	if endDate.Before(startDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "end_date must be after start_date",
+		})
+	}
+
+	history, err := h.accountService.GetHistory(uint(id), userID, startDate, endDate)
+	if err != nil {
+		return accountError(c, err, "Failed to fetch balance history")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// Create handles POST /accounts
+func (h *AccountHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input account.CreateAccountInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.accountService.Create(userID, input)
+	if err != nil {
+		return accountError(c, err, "Failed to create account")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT/PATCH /accounts/:id
+func (h *AccountHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid account ID",
+		})
+	}
+
+	var input account.UpdateAccountInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.accountService.Update(uint(id), userID, input)
+	if err != nil {
+		return accountError(c, err, "Failed to update account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /accounts/:id
+func (h *AccountHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid account ID",
+		})
+	}
+
+	if err := h.accountService.Delete(uint(id), userID); err != nil {
+		return accountError(c, err, "Failed to delete account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Account deleted successfully",
+	})
+}
+
+// GetTransfers handles GET /transfers (optional account_id filter)
+func (h *AccountHandler) GetTransfers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var accountID uint64
+	if value := c.Query("account_id"); value != "" {
+		var err error
+		if accountID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid account_id",
+			})
+		}
+	}
+
+	transfers, err := h.accountService.GetTransfers(userID, uint(accountID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch transfers",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    transfers,
+		"count":   len(transfers),
+	})
+}
+
+// CreateTransfer handles POST /transfers
+func (h *AccountHandler) CreateTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input account.TransferInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	transfer, err := h.accountService.CreateTransfer(userID, input)
+	if err != nil {
+		return accountError(c, err, "Failed to record transfer")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    transfer,
+	})
+}
+
+// DeleteTransfer handles DELETE /transfers/:id
+func (h *AccountHandler) DeleteTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid transfer ID",
+		})
+	}
+
+	if err := h.accountService.DeleteTransfer(uint(id), userID); err != nil {
+		return accountError(c, err, "Failed to delete transfer")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Transfer deleted successfully",
+	})
+}
+
+// accountError maps account service errors to HTTP responses
+func accountError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "account not found", "transfer not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "account is still in use":
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid account type":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid type (use cash, bank, card or wallet)",
+		})
+	case "invalid currency":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid currency (use a 3-letter ISO 4217 code)",
+		})
+	case "name is required", "amount must be positive", "cannot transfer to the same account",
+		"to_amount is required between accounts in different currencies":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
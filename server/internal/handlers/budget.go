@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/budget"
+)
+
+// BudgetHandler handles HTTP requests for budgets and budget alerts
+type BudgetHandler struct {
+	budgetService *budget.Service
+}
+
+// NewBudgetHandler creates a new budget handler
+func NewBudgetHandler(budgetService *budget.Service) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+// GetAll handles GET /budgets
+func (h *BudgetHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	budgets, err := h.budgetService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch budgets",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    budgets,
+		"count":   len(budgets),
+	})
+}
+
+// GetByID handles GET /budgets/:id
+func (h *BudgetHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid budget ID",
+		})
+	}
+
+	found, err := h.budgetService.GetByID(uint(id), userID)
+	if err != nil {
+		return budgetError(c, err, "Failed to fetch budget")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    found,
+	})
+}
+
+// GetStatuses handles GET /budgets/status for all currently active budgets
+func (h *BudgetHandler) GetStatuses(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	statuses, err := h.budgetService.GetStatuses(userID, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch budget status",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    statuses,
+		"count":   len(statuses),
+	})
+}
+
+// GetStatus handles GET /budgets/:id/status. The optional date (YYYY-MM-DD)
+// selects the period and defaults to today.
+func (h *BudgetHandler) GetStatus(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid budget ID",
+		})
+	}
+
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		if date, err = time.Parse("2006-01-02", value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid date format (use YYYY-MM-DD)",
+			})
+		}
+	}
+
+	status, err := h.budgetService.GetStatus(uint(id), userID, date)
+	if err != nil {
+		return budgetError(c, err, "Failed to fetch budget status")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Create handles POST /budgets
+func (h *BudgetHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input budget.CreateBudgetInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.budgetService.Create(userID, input)
+	if err != nil {
+		return budgetError(c, err, "Failed to create budget")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT/PATCH /budgets/:id
+func (h *BudgetHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid budget ID",
+		})
+	}
+
+	var input budget.UpdateBudgetInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.budgetService.Update(uint(id), userID, input)
+	if err != nil {
+		return budgetError(c, err, "Failed to update budget")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /budgets/:id
+func (h *BudgetHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid budget ID",
+		})
+	}
+
+	if err := h.budgetService.Delete(uint(id), userID); err != nil {
+		return budgetError(c, err, "Failed to delete budget")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Budget deleted successfully",
+	})
+}
+
+// GetAlerts handles GET /budgets/alerts (unread=true for unread alerts only)
+func (h *BudgetHandler) GetAlerts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	unreadOnly := false
+	if value := c.Query("unread"); value != "" {
+		var err error
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid unread (use true or false)",
+			})
+		}
+	}
+
+	alerts, err := h.budgetService.GetAlerts(userID, unreadOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch budget alerts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    alerts,
+		"count":   len(alerts),
+	})
+}
+
+// MarkAlertRead handles POST /budgets/alerts/:id/read
+func (h *BudgetHandler) MarkAlertRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid alert ID",
+		})
+	}
+
+	alert, err := h.budgetService.MarkAlertRead(uint(id), userID)
+	if err != nil {
+		return budgetError(c, err, "Failed to update budget alert")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    alert,
+	})
+}
+
+// budgetError maps budget service errors to HTTP responses
+func budgetError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "budget not found", "alert not found", "category not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid period":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid period (use weekly, monthly or custom)",
+		})
+	case "amount must be positive", "end_date is required for custom budgets",
+		"end_date must be after start_date", "budget is not active on this date":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/parvejmia9/minflow/server/internal/models"
@@ -42,6 +43,8 @@ func (h *CategoryHandler) GetAll(c *fiber.Ctx) error {
 
 // GetByID handles GET /categories/:id
 func (h *CategoryHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
 	// Get category ID from URL params
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
@@ -52,7 +55,7 @@ func (h *CategoryHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	category, err := h.categoryService.GetByID(uint(id))
+	category, err := h.categoryService.GetByID(uint(id), userID)
 	if err != nil {
 		if err.Error() == "category not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -66,6 +69,10 @@ func (h *CategoryHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
+	if notModified(c, etagOf(category.ID, category.UpdatedAt)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"data":    category,
@@ -103,3 +110,143 @@ func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 		"data":    category,
 	})
 }
+
+// Update handles PUT/PATCH /categories/:id
+// Only the user's own categories can be renamed.
+func (h *CategoryHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid category ID",
+		})
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	// Refuse to overwrite changes the client hasn't seen
+	var expected *time.Time
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		current, err := h.categoryService.GetByID(uint(id), userID)
+		if err != nil {
+			if err.Error() == "category not found" {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"success": false,
+					"error":   "Category not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch category",
+			})
+		}
+		if etag := etagOf(current.ID, current.UpdatedAt); ifMatchFailed(c, etag) {
+			return preconditionFailed(c, etag, "Category")
+		}
+		expected = &current.UpdatedAt
+	}
+
+	category, err := h.categoryService.Update(uint(id), userID, input.Name, expected)
+	if err != nil {
+		switch err.Error() {
+		case "category was modified":
+			return preconditionFailed(c, h.currentETag(uint(id), userID), "Category")
+		case "category not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Category not found",
+			})
+		case "name is required":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update category",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, etagOf(category.ID, category.UpdatedAt))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    category,
+	})
+}
+
+// Delete handles DELETE /categories/:id
+// Only the user's own categories can be deleted; they go to the trash.
+func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid category ID",
+		})
+	}
+
+	// Refuse to delete changes the client hasn't seen
+	var expected *time.Time
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		current, err := h.categoryService.GetByID(uint(id), userID)
+		if err != nil {
+			if err.Error() == "category not found" {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"success": false,
+					"error":   "Category not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch category",
+			})
+		}
+		if etag := etagOf(current.ID, current.UpdatedAt); ifMatchFailed(c, etag) {
+			return preconditionFailed(c, etag, "Category")
+		}
+		expected = &current.UpdatedAt
+	}
+
+	if err := h.categoryService.Delete(uint(id), userID, expected); err != nil {
+		if err.Error() == "category was modified" {
+			return preconditionFailed(c, h.currentETag(uint(id), userID), "Category")
+		}
+		if err.Error() == "category not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Category not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to delete category",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Category moved to trash",
+	})
+}
+
+// currentETag returns the ETag of a category, or "" when it can't be loaded
+func (h *CategoryHandler) currentETag(id, userID uint) string {
+	category, err := h.categoryService.GetByID(id, userID)
+	if err != nil {
+		return ""
+	}
+	return etagOf(category.ID, category.UpdatedAt)
+}
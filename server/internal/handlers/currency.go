@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+)
+
+// CurrencyHandler handles HTTP requests for exchange rates
+type CurrencyHandler struct {
+	currencyService *currency.Service
+}
+
+// NewCurrencyHandler creates a new currency handler
+func NewCurrencyHandler(currencyService *currency.Service) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyService: currencyService,
+	}
+}
+
+// GetRates handles GET /exchange-rates
+func (h *CurrencyHandler) GetRates(c *fiber.Ctx) error {
+	from := strings.ToUpper(c.Query("from"))
+	to := strings.ToUpper(c.Query("to"))
+
+	rates, err := h.currencyService.GetRates(from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch exchange rates",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rates,
+		"count":   len(rates),
+	})
+}
+
+// SetRate handles POST /exchange-rates (admin only)
+func (h *CurrencyHandler) SetRate(c *fiber.Ctx) error {
+	var input currency.RateInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	rate, err := h.currencyService.SetRate(input)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") || strings.HasSuffix(err.Error(), "must differ") || strings.HasSuffix(err.Error(), "must be positive") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to save exchange rate",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rate,
+	})
+}
+
+// ImportRates handles POST /exchange-rates/import (admin only)
+// The multipart "file" field holds CSV lines of date,from_currency,to_currency,rate.
+func (h *CurrencyHandler) ImportRates(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "CSV file is required (form field \"file\")",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	loaded, err := h.currencyService.Load(file)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+			"loaded":  loaded,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"loaded":  loaded,
+	})
+}
+
+// DeleteRate handles DELETE /exchange-rates/:id (admin only)
+func (h *CurrencyHandler) DeleteRate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid exchange rate ID",
+		})
+	}
+
+	if err := h.currencyService.DeleteRate(uint(id)); err != nil {
+		if err.Error() == "exchange rate not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to delete exchange rate",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Exchange rate deleted successfully",
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// etagOf derives the ETag of a record from its ID and last update. Times
+// are compared at microsecond precision, as stored by Postgres.
+func etagOf(id uint, updatedAt time.Time) string {
+	return `"` + strconv.FormatUint(uint64(id), 10) + "-" + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header value
+// lists etag. "*" matches any ETag, and weak validators compare like
+// strong ones.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header and reports whether the request's
+// If-None-Match already lists it, in which case a 304 should be sent
+func notModified(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderETag, etag)
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && etagMatches(header, etag)
+}
+
+// ifMatchFailed reports whether the request has an If-Match header that
+// doesn't list the current ETag. Requests without If-Match always pass.
+func ifMatchFailed(c *fiber.Ctx, etag string) bool {
+	header := c.Get(fiber.HeaderIfMatch)
+	return header != "" && !etagMatches(header, etag)
+}
+
+// preconditionFailed answers 412 with the current ETag, if known
+func preconditionFailed(c *fiber.Ctx, etag, resource string) error {
+	if etag != "" {
+		c.Set(fiber.HeaderETag, etag)
+	}
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"success": false,
+		"error":   resource + " was modified since it was fetched; reload it and try again",
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestETagOf(t *testing.T) {
+	updated := time.Date(2026, 10, 16, 9, 30, 0, 123456789, time.UTC)
+
+	etag := etagOf(42, updated)
+	if want := `"42-` + strconv.FormatInt(updated.UnixMicro(), 36) + `"`; etag != want {
+		t.Fatalf("etagOf() = %s, want %s", etag, want)
+	}
+	// Postgres keeps microseconds, so a reloaded record has the same ETag
+	if reloaded := etagOf(42, updated.Truncate(time.Microsecond)); reloaded != etag {
+		t.Errorf("etagOf() after reload = %s, want %s", reloaded, etag)
+	}
+	if in := etagOf(42, updated.In(time.FixedZone("BDT", 6*3600))); in != etag {
+		t.Errorf("etagOf() in another zone = %s, want %s", in, etag)
+	}
+	if later := etagOf(42, updated.Add(time.Microsecond)); later == etag {
+		t.Error("etagOf() should change with every update")
+	}
+	if other := etagOf(43, updated); other == etag {
+		t.Error("etagOf() should differ between records")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"42-abc"`
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"42-abc"`, true},
+		{`W/"42-abc"`, true},
+		{`"1-x", "42-abc"`, true},
+		{`"1-x",W/"42-abc"`, true},
+		{`*`, true},
+		{` * `, true},
+		{`"42-abd"`, false},
+		{`42-abc`, false},
+		{`"1-x", "2-y"`, false},
+		{``, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,20 @@
 package handlers
 
 import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/export"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/pagination"
 	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"github.com/parvejmia9/minflow/server/internal/services/tag"
 )
 
 // ExpenseHandler handles HTTP requests for expenses
@@ -20,7 +29,8 @@ func NewExpenseHandler(expenseService *expense.Service) *ExpenseHandler {
 	}
 }
 
-// Create handles POST /expenses
+// Create handles POST /expenses. Like bulk creates and imports, it saves
+// expenses that look like duplicates and lists the matches under "warnings".
 func (h *ExpenseHandler) Create(c *fiber.Ctx) error {
 	// Get user ID from context (set by auth middleware)
 	userID := c.Locals("userID").(uint)
@@ -41,23 +51,164 @@ func (h *ExpenseHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
-	expense, err := h.expenseService.Create(userID, input)
+	expense, warnings, err := h.expenseService.Create(userID, input)
 	if err != nil {
-		if err.Error() == "category not found" {
+		if err.Error() == "category not found" || err.Error() == "account not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"success": false,
 				"error":   err.Error(),
 			})
 		}
+		if err.Error() == "invalid currency" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid currency (use a 3-letter ISO 4217 code)",
+			})
+		}
+		if err.Error() == "currency must match the account currency" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		if err.Error() == "invalid tag name" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid tag name (1-50 characters, no commas)",
+			})
+		}
+		if err.Error() == "expense with this external_id already exists" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"error":   "Failed to create expense",
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	response := fiber.Map{
 		"success": true,
 		"data":    expense,
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// maxBulkExpenses caps the number of rows accepted by a bulk create
+const maxBulkExpenses = 500
+
+// CreateBulk handles POST /expenses/bulk. Rows that look like duplicates are
+// saved and listed under "warnings" by their index.
+func (h *ExpenseHandler) CreateBulk(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var inputs []expense.CreateExpenseInput
+	if err := c.BodyParser(&inputs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body (expected an array of expenses)",
+		})
+	}
+
+	if len(inputs) == 0 || len(inputs) > maxBulkExpenses {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Between 1 and " + strconv.Itoa(maxBulkExpenses) + " expenses are required",
+		})
+	}
+
+	expenses, warnings, err := h.expenseService.CreateBulk(userID, inputs)
+	if err != nil {
+		var validationErr *expense.BulkValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"success": false,
+				"error":   "One or more expenses are invalid",
+				"errors":  validationErr.Rows,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create expenses",
+		})
+	}
+
+	response := fiber.Map{
+		"success": true,
+		"data":    expenses,
+		"count":   len(expenses),
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// Batch handles POST /expenses/batch. The body names the action and its
+// argument, and either the expense ids or nothing, in which case the
+// GetAll filters in the query string select the expenses.
+func (h *ExpenseHandler) Batch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input expense.BatchInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	filter, err := parseExpenseFilter(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	result, err := h.expenseService.Batch(filter, input)
+	if err != nil {
+		switch err.Error() {
+		case "category not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		case "invalid batch action":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid action (use recategorize, delete, shift_date or add_tag)",
+			})
+		case "too many expenses selected":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Too many expenses selected (at most " + strconv.Itoa(expense.MaxBatchSize) + ")",
+			})
+		case "invalid tag name":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid tag name (1-50 characters, no commas)",
+			})
+		case "ids or a filter is required", "days must not be zero":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to apply batch",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
 	})
 }
 
@@ -72,8 +223,55 @@ func (h *ExpenseHandler) GetAll(c *fiber.Ctx) error {
 	if limit > 100 {
 		limit = 100
 	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	filter, err := parseExpenseFilter(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	// Cursor mode is selected by passing a cursor, or pagination=cursor for the first page
+	cursorParam := c.Query("cursor")
+	if cursorParam != "" || c.Query("pagination") == "cursor" {
+		var cursor *pagination.Cursor
+		if cursorParam != "" {
+			cursor, err = pagination.Decode(cursorParam)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   "Invalid cursor",
+				})
+			}
+		}
+
+		expenses, nextCursor, err := h.expenseService.GetByUserAfter(filter, limit, cursor)
+		if err != nil {
+			if err.Error() == "cursor pagination only supports sorting by expense_date" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch expenses",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"data":        expenses,
+			"limit":       limit,
+			"next_cursor": nextCursor,
+		})
+	}
 
-	expenses, total, err := h.expenseService.GetByUser(userID, limit, offset)
+	expenses, total, err := h.expenseService.GetByUser(filter, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -90,6 +288,255 @@ func (h *ExpenseHandler) GetAll(c *fiber.Ctx) error {
 	})
 }
 
+// Search handles GET /expenses/search?q= with full-text matching on name,
+// merchant, category and notes. Results are ranked, every word matches as a
+// prefix, and the other GetAll filters and pagination params still apply.
+func (h *ExpenseHandler) Search(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "q is required",
+		})
+	}
+
+	// Parse pagination params
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	filter, err := parseExpenseFilter(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	results, total, err := h.expenseService.Search(filter, text, limit, offset)
+	if err != nil {
+		if err.Error() == "search query is required" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "q must contain at least one word",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to search expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// parseExpenseFilter builds an expense filter from the query string.
+// Supported params: category_ids, account_ids and merchant_ids (comma
+// separated), start_date, end_date (YYYY-MM-DD, inclusive), min_total,
+// max_total, q, sort_by and sort_dir.
+func parseExpenseFilter(c *fiber.Ctx, userID uint) (expense.ExpenseFilter, error) {
+	filter := expense.ExpenseFilter{
+		UserID: userID,
+		Search: strings.TrimSpace(c.Query("q")),
+	}
+
+	if categoryIDs := c.Query("category_ids"); categoryIDs != "" {
+		for _, part := range strings.Split(categoryIDs, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				return filter, errors.New("Invalid category_ids (use comma separated IDs)")
+			}
+			filter.CategoryIDs = append(filter.CategoryIDs, uint(id))
+		}
+	}
+
+	if accountIDs := c.Query("account_ids"); accountIDs != "" {
+		for _, part := range strings.Split(accountIDs, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				return filter, errors.New("Invalid account_ids (use comma separated IDs)")
+			}
+			filter.AccountIDs = append(filter.AccountIDs, uint(id))
+		}
+	}
+
+	if merchantIDs := c.Query("merchant_ids"); merchantIDs != "" {
+		for _, part := range strings.Split(merchantIDs, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				return filter, errors.New("Invalid merchant_ids (use comma separated IDs)")
+			}
+			filter.MerchantIDs = append(filter.MerchantIDs, uint(id))
+		}
+	}
+
+	if tags := c.Query("tags"); tags != "" {
+		names, err := tag.NormalizeNames(strings.Split(tags, ","))
+		if err != nil {
+			return filter, errors.New("Invalid tags (use comma separated tag names)")
+		}
+		filter.Tags = names
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			return filter, errors.New("Invalid start_date format (use YYYY-MM-DD)")
+		}
+		filter.StartDate = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			return filter, errors.New("Invalid end_date format (use YYYY-MM-DD)")
+		}
+		// Include the whole end day
+		endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		filter.EndDate = &endDate
+	}
+
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return filter, errors.New("end_date must be after start_date")
+	}
+
+	if minTotalStr := c.Query("min_total"); minTotalStr != "" {
+		minTotal, err := money.Parse(minTotalStr)
+		if err != nil {
+			return filter, errors.New("Invalid min_total")
+		}
+		filter.MinTotal = &minTotal
+	}
+
+	if maxTotalStr := c.Query("max_total"); maxTotalStr != "" {
+		maxTotal, err := money.Parse(maxTotalStr)
+		if err != nil {
+			return filter, errors.New("Invalid max_total")
+		}
+		filter.MaxTotal = &maxTotal
+	}
+
+	if sortBy := c.Query("sort_by"); sortBy != "" {
+		if !expense.ValidSortField(sortBy) {
+			return filter, errors.New("Invalid sort_by (use expense_date, total, name or created_at)")
+		}
+		filter.SortBy = sortBy
+	}
+
+	if sortDir := strings.ToLower(c.Query("sort_dir")); sortDir != "" {
+		if sortDir != "asc" && sortDir != "desc" {
+			return filter, errors.New("Invalid sort_dir (use asc or desc)")
+		}
+		filter.SortDir = sortDir
+	}
+
+	return filter, nil
+}
+
+// exportHeader lists the columns of an expense export
+var exportHeader = []string{"id", "expense_date", "name", "category_id", "category_name", "unit", "per_unit_cost", "total", "currency", "merchant", "created_at"}
+
+// Export handles GET /expenses/export?format=csv|json|xlsx
+func (h *ExpenseHandler) Export(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	formatName := strings.ToLower(c.Query("format", "csv"))
+	format, ok := export.Formats[formatName]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid format (use csv, json or xlsx)",
+		})
+	}
+
+	filter, err := parseExpenseFilter(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	filename := "expenses-" + time.Now().Format("2006-01-02") + "." + format.Extension
+	c.Set(fiber.HeaderContentType, format.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	// The export outlives the handler, and query strings point into the
+	// request buffer that fasthttp reuses once the handler returns
+	formatName = strings.Clone(formatName)
+	for i, tag := range filter.Tags {
+		filter.Tags[i] = strings.Clone(tag)
+	}
+	filter.Search = strings.Clone(filter.Search)
+	filter.SortBy = strings.Clone(filter.SortBy)
+	filter.SortDir = strings.Clone(filter.SortDir)
+
+	// Stream through a pipe so a failure part way aborts the response: the
+	// chunked body then ends without its final chunk and clients see a
+	// broken transfer instead of a truncated file with a 200
+	pr, pw := io.Pipe()
+	go func() {
+		err := h.writeExport(pw, format, filter)
+		if err != nil {
+			log.Printf("[ERROR] Failed to export expenses as %s for user %d: %v", formatName, userID, err)
+		}
+		pw.CloseWithError(err)
+	}()
+	c.Context().SetBodyStream(pr, -1)
+
+	return nil
+}
+
+// writeExport writes the expenses matching the filter to w in the given format
+func (h *ExpenseHandler) writeExport(w io.Writer, format export.Format, filter expense.ExpenseFilter) error {
+	bw := bufio.NewWriter(w)
+	rowWriter, err := format.New(bw, exportHeader)
+	if err != nil {
+		return err
+	}
+
+	err = h.expenseService.Export(filter, func(row expense.ExportRow) error {
+		return rowWriter.WriteRow([]export.Cell{
+			export.Number(float64(row.ID)),
+			export.Text(row.ExpenseDate.Format("2006-01-02")),
+			export.Text(row.Name),
+			export.Number(float64(row.CategoryID)),
+			export.Text(row.CategoryName),
+			export.Number(row.Unit),
+			export.Decimal(row.PerUnitCost.String()),
+			export.Decimal(row.Total.String()),
+			export.Text(row.Currency),
+			export.Text(row.MerchantName),
+			export.Text(row.CreatedAt.Format(time.RFC3339)),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := rowWriter.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
 // GetByID handles GET /expenses/:id
 func (h *ExpenseHandler) GetByID(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -117,6 +564,10 @@ func (h *ExpenseHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
+	if notModified(c, etagOf(expense.ID, expense.UpdatedAt)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"data":    expense,
@@ -147,6 +598,38 @@ func (h *ExpenseHandler) GetDateRange(c *fiber.Ctx) error {
 	})
 }
 
+// GetDuplicates handles GET /expenses/duplicates
+func (h *ExpenseHandler) GetDuplicates(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	// Optional override of the configured duplicate window
+	var window time.Duration
+	if value := c.Query("window_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "window_days must be a positive number",
+			})
+		}
+		window = time.Duration(days) * 24 * time.Hour
+	}
+
+	groups, err := h.expenseService.GetDuplicateGroups(userID, window)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to find duplicate expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    groups,
+		"count":   len(groups),
+	})
+}
+
 // GetAnalytics handles GET /expenses/analytics
 func (h *ExpenseHandler) GetAnalytics(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -209,6 +692,17 @@ func (h *ExpenseHandler) GetAnalytics(c *fiber.Ctx) error {
 		EndDate:   endDate,
 	}
 
+	if tags := c.Query("tags"); tags != "" {
+		names, err := tag.NormalizeNames(strings.Split(tags, ","))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid tags (use comma separated tag names)",
+			})
+		}
+		query.Tags = names
+	}
+
 	analytics, err := h.expenseService.GetAnalytics(query)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -223,6 +717,102 @@ func (h *ExpenseHandler) GetAnalytics(c *fiber.Ctx) error {
 	})
 }
 
+// Update handles PUT/PATCH /expenses/:id
+func (h *ExpenseHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	var input expense.UpdateExpenseInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	// Validate supplied fields
+	if (input.Name != nil && *input.Name == "") ||
+		(input.CategoryID != nil && *input.CategoryID == 0) ||
+		(input.Unit != nil && *input.Unit <= 0) ||
+		(input.PerUnitCost != nil && *input.PerUnitCost <= 0) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Name and category_id cannot be empty; unit and per_unit_cost must be positive",
+		})
+	}
+
+	// Refuse to overwrite changes the client hasn't seen
+	var expected *time.Time
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		current, err := h.expenseService.GetByID(uint(id), userID)
+		if err != nil {
+			if err.Error() == "expense not found" {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch expense",
+			})
+		}
+		if etag := etagOf(current.ID, current.UpdatedAt); ifMatchFailed(c, etag) {
+			return preconditionFailed(c, etag, "Expense")
+		}
+		expected = &current.UpdatedAt
+	}
+
+	expense, err := h.expenseService.Update(uint(id), userID, input, expected)
+	if err != nil {
+		if err.Error() == "expense was modified" {
+			return preconditionFailed(c, h.currentETag(uint(id), userID), "Expense")
+		}
+		if err.Error() == "expense not found" || err.Error() == "category not found" || err.Error() == "account not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		if err.Error() == "invalid currency" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid currency (use a 3-letter ISO 4217 code)",
+			})
+		}
+		if err.Error() == "currency must match the account currency" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		if err.Error() == "invalid tag name" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid tag name (1-50 characters, no commas)",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update expense",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, etagOf(expense.ID, expense.UpdatedAt))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    expense,
+	})
+}
+
 // Delete handles DELETE /expenses/:id
 func (h *ExpenseHandler) Delete(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -236,8 +826,33 @@ func (h *ExpenseHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.expenseService.Delete(uint(id), userID)
+	// Refuse to delete changes the client hasn't seen
+	var expected *time.Time
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		current, err := h.expenseService.GetByID(uint(id), userID)
+		if err != nil {
+			if err.Error() == "expense not found" {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch expense",
+			})
+		}
+		if etag := etagOf(current.ID, current.UpdatedAt); ifMatchFailed(c, etag) {
+			return preconditionFailed(c, etag, "Expense")
+		}
+		expected = &current.UpdatedAt
+	}
+
+	err = h.expenseService.Delete(uint(id), userID, expected)
 	if err != nil {
+		if err.Error() == "expense was modified" {
+			return preconditionFailed(c, h.currentETag(uint(id), userID), "Expense")
+		}
 		if err.Error() == "expense not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"success": false,
@@ -252,6 +867,15 @@ func (h *ExpenseHandler) Delete(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Expense deleted successfully",
+		"message": "Expense moved to trash",
 	})
 }
+
+// currentETag returns the ETag of an expense, or "" when it can't be loaded
+func (h *ExpenseHandler) currentETag(id, userID uint) string {
+	expense, err := h.expenseService.GetByID(id, userID)
+	if err != nil {
+		return ""
+	}
+	return etagOf(expense.ID, expense.UpdatedAt)
+}
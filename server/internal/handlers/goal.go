@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/goal"
+)
+
+// GoalHandler handles HTTP requests for savings goals and contributions
+type GoalHandler struct {
+	goalService *goal.Service
+}
+
+// NewGoalHandler creates a new goal handler
+func NewGoalHandler(goalService *goal.Service) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+	}
+}
+
+// GetAll handles GET /goals
+func (h *GoalHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	goals, err := h.goalService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch goals",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    goals,
+		"count":   len(goals),
+	})
+}
+
+// GetByID handles GET /goals/:id
+func (h *GoalHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	found, err := h.goalService.GetByID(uint(id), userID)
+	if err != nil {
+		return goalError(c, err, "Failed to fetch goal")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    found,
+	})
+}
+
+// Create handles POST /goals
+func (h *GoalHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input goal.CreateGoalInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.goalService.Create(userID, input)
+	if err != nil {
+		return goalError(c, err, "Failed to create goal")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT/PATCH /goals/:id
+func (h *GoalHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	var input goal.UpdateGoalInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.goalService.Update(uint(id), userID, input)
+	if err != nil {
+		return goalError(c, err, "Failed to update goal")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /goals/:id
+func (h *GoalHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	if err := h.goalService.Delete(uint(id), userID); err != nil {
+		return goalError(c, err, "Failed to delete goal")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Goal deleted successfully",
+	})
+}
+
+// GetContributions handles GET /goals/:id/contributions
+func (h *GoalHandler) GetContributions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	contributions, err := h.goalService.GetContributions(uint(id), userID)
+	if err != nil {
+		return goalError(c, err, "Failed to fetch contributions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    contributions,
+		"count":   len(contributions),
+	})
+}
+
+// AddContribution handles POST /goals/:id/contributions
+func (h *GoalHandler) AddContribution(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	var input goal.ContributionInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	contribution, err := h.goalService.AddContribution(uint(id), userID, input)
+	if err != nil {
+		return goalError(c, err, "Failed to record contribution")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    contribution,
+	})
+}
+
+// DeleteContribution handles DELETE /goals/:id/contributions/:contributionId
+func (h *GoalHandler) DeleteContribution(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	contributionID, err := strconv.ParseUint(c.Params("contributionId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid contribution ID",
+		})
+	}
+
+	if err := h.goalService.DeleteContribution(uint(id), uint(contributionID), userID); err != nil {
+		return goalError(c, err, "Failed to delete contribution")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contribution deleted successfully",
+	})
+}
+
+// GetProjection handles GET /goals/:id/projection. The optional days param
+// sets how many recent days the pace is measured over.
+func (h *GoalHandler) GetProjection(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid goal ID",
+		})
+	}
+
+	days := goal.DefaultProjectionDays
+	if value := c.Query("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 1 || days > goal.MaxProjectionDays {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid days (use 1 to " + strconv.Itoa(goal.MaxProjectionDays) + ")",
+			})
+		}
+	}
+
+	projection, err := h.goalService.GetProjection(uint(id), userID, time.Now(), days)
+	if err != nil {
+		return goalError(c, err, "Failed to project goal")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    projection,
+	})
+}
+
+// goalError maps goal service errors to HTTP responses
+func goalError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "goal not found", "contribution not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "name is required", "target amount must be positive", "amount must be positive":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"github.com/parvejmia9/minflow/server/internal/services/importer"
+)
+
+// ImportHandler handles HTTP requests for importing expenses
+type ImportHandler struct {
+	importService *importer.Service
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(importService *importer.Service) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportCSV handles POST /expenses/import/csv
+//
+// Multipart form fields:
+//   - file: the CSV file
+//   - mapping: JSON object mapping name, category_id or category, unit,
+//     per_unit_cost or amount, and expense_date to header names or indexes
+//   - date_format: e.g. YYYY-MM-DD (default) or DD/MM/YYYY
+//   - delimiter: a single character or "tab" (default ",")
+//   - has_header: "false" when the first line is data (default "true")
+//   - dry_run: "true" to only return the parsed rows and errors
+func (h *ImportHandler) ImportCSV(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "CSV file is required (form field \"file\")",
+		})
+	}
+
+	var mapping importer.ColumnMapping
+	if err := json.Unmarshal([]byte(c.FormValue("mapping")), &mapping); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid mapping (expected a JSON object)",
+		})
+	}
+
+	delimiter, err := importer.ParseDelimiter(c.FormValue("delimiter"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	opts := importer.CSVOptions{
+		Mapping:    mapping,
+		DateFormat: c.FormValue("date_format"),
+		Delimiter:  delimiter,
+		HasHeader:  c.FormValue("has_header", "true") != "false",
+	}
+	dryRun := c.FormValue("dry_run") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	result, err := h.importService.ParseCSV(userID, file, opts)
+	if err != nil {
+		return importError(c, err)
+	}
+
+	return h.finishImport(c, userID, result, dryRun)
+}
+
+// ImportBankStatement handles POST /expenses/import/bank
+//
+// Multipart form fields:
+//   - file: an OFX, QFX or QIF statement
+//   - format: ofx, qfx or qif (default: taken from the file extension)
+//   - date_format: date format of QIF files, e.g. DD/MM/YYYY (default MM/DD/YYYY)
+//   - dry_run: "true" to only return the parsed rows and errors
+//
+// Only debits are imported. Transactions imported before are skipped.
+func (h *ImportHandler) ImportBankStatement(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Statement file is required (form field \"file\")",
+		})
+	}
+
+	format := strings.ToLower(c.FormValue("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	dryRun := c.FormValue("dry_run") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	var transactions []importer.BankTransaction
+	var lineErrors []importer.LineError
+	switch format {
+	case "ofx", "qfx":
+		transactions, lineErrors, err = importer.ParseOFX(file)
+	case "qif":
+		transactions, lineErrors, err = importer.ParseQIF(file, c.FormValue("date_format"))
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unsupported format (use ofx, qfx or qif)",
+		})
+	}
+	if err != nil {
+		return importError(c, err)
+	}
+
+	result, err := h.importService.PrepareBankImport(userID, transactions, lineErrors)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to prepare import",
+		})
+	}
+
+	// Re-importing a statement that only has known transactions is not an error
+	if !dryRun && len(result.Rows) == 0 && len(result.Errors) == 0 {
+		result.DryRun = false
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+		})
+	}
+
+	return h.finishImport(c, userID, result, dryRun)
+}
+
+// finishImport returns a dry-run preview or commits the parsed rows. Rows
+// that look like duplicates are flagged in both, but never block the import.
+func (h *ImportHandler) finishImport(c *fiber.Ctx, userID uint, result *importer.ImportResult, dryRun bool) error {
+	if dryRun {
+		if _, err := h.importService.FindDuplicates(userID, result); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to check for duplicates",
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+		})
+	}
+
+	if len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"error":   "Some lines could not be imported; nothing was saved",
+			"data":    result,
+		})
+	}
+
+	if len(result.Rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "No expenses found in file",
+		})
+	}
+
+	result.DryRun = false
+	if err := h.importService.Commit(userID, result); err != nil {
+		var validationErr *expense.BulkValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"success": false,
+				"error":   "One or more expenses are invalid",
+				"errors":  validationErr.Rows,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to import expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// importError maps parser errors to HTTP responses
+func importError(c *fiber.Ctx, err error) error {
+	var fileErr importer.InvalidFileError
+	if errors.As(err, &fileErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fileErr.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to parse file",
+	})
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/income"
+)
+
+// IncomeHandler handles HTTP requests for incomes and cash-flow analytics
+type IncomeHandler struct {
+	incomeService *income.Service
+}
+
+// NewIncomeHandler creates a new income handler
+func NewIncomeHandler(incomeService *income.Service) *IncomeHandler {
+	return &IncomeHandler{
+		incomeService: incomeService,
+	}
+}
+
+// GetAll handles GET /incomes. Supported params: start_date, end_date
+// (YYYY-MM-DD, inclusive) and recurring.
+func (h *IncomeHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	filter := income.IncomeFilter{UserID: userID}
+	if value := c.Query("start_date"); value != "" {
+		startDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid start_date format (use YYYY-MM-DD)",
+			})
+		}
+		filter.StartDate = &startDate
+	}
+	if value := c.Query("end_date"); value != "" {
+		endDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid end_date format (use YYYY-MM-DD)",
+			})
+		}
+		// Include the whole end day
+		endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		filter.EndDate = &endDate
+	}
+	if value := c.Query("recurring"); value != "" {
+		recurring, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid recurring (use true or false)",
+			})
+		}
+		filter.Recurring = &recurring
+	}
+
+	incomes, err := h.incomeService.GetByUser(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch incomes",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    incomes,
+		"count":   len(incomes),
+	})
+}
+
+// GetByID handles GET /incomes/:id
+func (h *IncomeHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid income ID",
+		})
+	}
+
+	found, err := h.incomeService.GetByID(uint(id), userID)
+	if err != nil {
+		return incomeError(c, err, "Failed to fetch income")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    found,
+	})
+}
+
+// Create handles POST /incomes
+func (h *IncomeHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input income.CreateIncomeInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.incomeService.Create(userID, input)
+	if err != nil {
+		return incomeError(c, err, "Failed to create income")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT/PATCH /incomes/:id
+func (h *IncomeHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid income ID",
+		})
+	}
+
+	var input income.UpdateIncomeInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.incomeService.Update(uint(id), userID, input)
+	if err != nil {
+		return incomeError(c, err, "Failed to update income")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /incomes/:id
+func (h *IncomeHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid income ID",
+		})
+	}
+
+	if err := h.incomeService.Delete(uint(id), userID); err != nil {
+		return incomeError(c, err, "Failed to delete income")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Income deleted successfully",
+	})
+}
+
+// GetCashFlow handles GET /cash-flow?start_date=&end_date=&interval=day|month
+func (h *IncomeHandler) GetCashFlow(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "start_date and end_date are required (format: YYYY-MM-DD)",
+		})
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid start_date format (use YYYY-MM-DD)",
+		})
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid end_date format (use YYYY-MM-DD)",
+		})
+	}
+
+	if endDate.Before(startDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "end_date must be after start_date",
+		})
+	}
+
+	interval := c.Query("interval", income.IntervalDay)
+	if !income.ValidInterval(interval) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid interval (use day or month)",
+		})
+	}
+
+	// One period per day or month, so cap the range
+	maxPeriods, unit := income.MaxCashFlowDays, "days"
+	if interval == income.IntervalMonth {
+		maxPeriods, unit = income.MaxCashFlowMonths, "months"
+	}
+	if income.PeriodCount(startDate, endDate, interval) > maxPeriods {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Date range is too long for interval " + interval + " (at most " + strconv.Itoa(maxPeriods) + " " + unit + ")",
+		})
+	}
+
+	// Set end date to end of day for query
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	cashFlow, err := h.incomeService.GetCashFlow(income.CashFlowQuery{
+		UserID:    userID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Interval:  interval,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate cash flow",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    cashFlow,
+	})
+}
+
+// incomeError maps income service errors to HTTP responses
+func incomeError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "income not found", "account not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid currency":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid currency (use a 3-letter ISO 4217 code)",
+		})
+	case "source is required", "amount must be positive", "currency must match the account currency":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
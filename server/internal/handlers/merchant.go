@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/merchant"
+)
+
+// MerchantHandler handles HTTP requests for merchants and their alias rules
+type MerchantHandler struct {
+	merchantService *merchant.Service
+}
+
+// NewMerchantHandler creates a new merchant handler
+func NewMerchantHandler(merchantService *merchant.Service) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+	}
+}
+
+// GetAll handles GET /merchants
+func (h *MerchantHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	merchants, err := h.merchantService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch merchants",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    merchants,
+		"count":   len(merchants),
+	})
+}
+
+// Normalize handles GET /merchants/normalize?raw=...
+func (h *MerchantHandler) Normalize(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	raw := c.Query("raw")
+	if raw == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "raw is required",
+		})
+	}
+
+	result, err := h.merchantService.Normalize(userID, raw)
+	if err != nil {
+		return merchantError(c, err, "Failed to normalize merchant")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Create handles POST /merchants
+func (h *MerchantHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input merchant.MerchantInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.merchantService.Create(userID, input)
+	if err != nil {
+		return merchantError(c, err, "Failed to create merchant")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT /merchants/:id
+func (h *MerchantHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid merchant ID",
+		})
+	}
+
+	var input merchant.MerchantInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.merchantService.Update(uint(id), userID, input)
+	if err != nil {
+		return merchantError(c, err, "Failed to update merchant")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /merchants/:id
+func (h *MerchantHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid merchant ID",
+		})
+	}
+
+	if err := h.merchantService.Delete(uint(id), userID); err != nil {
+		return merchantError(c, err, "Failed to delete merchant")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Merchant deleted successfully",
+	})
+}
+
+// GetAliases handles GET /merchants/aliases
+func (h *MerchantHandler) GetAliases(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	aliases, err := h.merchantService.GetAliases(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch merchant aliases",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    aliases,
+		"count":   len(aliases),
+	})
+}
+
+// CreateAlias handles POST /merchants/aliases
+func (h *MerchantHandler) CreateAlias(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input merchant.AliasInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.merchantService.CreateAlias(userID, input)
+	if err != nil {
+		return merchantError(c, err, "Failed to create merchant alias")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// UpdateAlias handles PUT /merchants/aliases/:id
+func (h *MerchantHandler) UpdateAlias(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid alias ID",
+		})
+	}
+
+	var input merchant.AliasInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.merchantService.UpdateAlias(uint(id), userID, input)
+	if err != nil {
+		return merchantError(c, err, "Failed to update merchant alias")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteAlias handles DELETE /merchants/aliases/:id
+func (h *MerchantHandler) DeleteAlias(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid alias ID",
+		})
+	}
+
+	if err := h.merchantService.DeleteAlias(uint(id), userID); err != nil {
+		return merchantError(c, err, "Failed to delete merchant alias")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Merchant alias deleted successfully",
+	})
+}
+
+// merchantError maps merchant service errors to HTTP responses
+func merchantError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "merchant not found", "alias not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "merchant already exists":
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid merchant name":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid merchant name (1-100 characters)",
+		})
+	case "invalid pattern":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid pattern (1-255 characters, valid regular expression for regex rules)",
+		})
+	case "invalid match type":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid match_type (use exact, prefix, contains or regex)",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"log"
+	"mime"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/receipt"
+)
+
+// ReceiptHandler handles HTTP requests for receipt attachments
+type ReceiptHandler struct {
+	receiptService *receipt.Service
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService *receipt.Service) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// Upload handles POST /expenses/:id/receipts
+// The multipart "file" field holds a JPEG, PNG, GIF, WebP or PDF file.
+func (h *ReceiptHandler) Upload(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Receipt file is required (form field \"file\")",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	uploaded, err := h.receiptService.Upload(c.UserContext(), userID, uint(expenseID), fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		return h.receiptError(c, err, "Failed to upload receipt")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    uploaded,
+	})
+}
+
+// GetAll handles GET /expenses/:id/receipts
+func (h *ReceiptHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	receipts, err := h.receiptService.GetByExpense(userID, uint(expenseID))
+	if err != nil {
+		return h.receiptError(c, err, "Failed to fetch receipts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    receipts,
+		"count":   len(receipts),
+	})
+}
+
+// Download handles GET /expenses/:id/receipts/:receiptId
+func (h *ReceiptHandler) Download(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenseID, receiptID, ok := parseReceiptParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense or receipt ID",
+		})
+	}
+
+	found, content, err := h.receiptService.Open(c.UserContext(), userID, expenseID, receiptID)
+	if err != nil {
+		return h.receiptError(c, err, "Failed to download receipt")
+	}
+
+	disposition := "inline"
+	if c.Query("download") == "true" {
+		disposition = "attachment"
+	}
+
+	c.Set(fiber.HeaderContentType, found.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": found.FileName}))
+	c.Set("X-Content-Type-Options", "nosniff")
+
+	// The stream is closed once the response has been written
+	return c.SendStream(content, int(found.Size))
+}
+
+// Delete handles DELETE /expenses/:id/receipts/:receiptId
+func (h *ReceiptHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenseID, receiptID, ok := parseReceiptParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense or receipt ID",
+		})
+	}
+
+	if err := h.receiptService.Delete(c.UserContext(), userID, expenseID, receiptID); err != nil {
+		return h.receiptError(c, err, "Failed to delete receipt")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Receipt deleted successfully",
+	})
+}
+
+// parseReceiptParams reads the expense and receipt IDs from the path
+func parseReceiptParams(c *fiber.Ctx) (uint, uint, bool) {
+	expenseID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	receiptID, err := strconv.ParseUint(c.Params("receiptId"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(expenseID), uint(receiptID), true
+}
+
+// receiptError maps receipt service errors to HTTP responses
+func (h *ReceiptHandler) receiptError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "expense not found", "receipt not found", "receipt file not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "file is empty":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "file is too large":
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"success":  false,
+			"error":    err.Error(),
+			"max_size": h.receiptService.MaxSize(),
+		})
+	case "unsupported file type":
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"success": false,
+			"error":   "unsupported file type (allowed: JPEG, PNG, GIF, WebP, PDF)",
+		})
+	}
+
+	log.Printf("receipts: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
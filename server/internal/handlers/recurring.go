@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/recurring"
+)
+
+// RecurringExpenseHandler handles HTTP requests for recurring expenses
+type RecurringExpenseHandler struct {
+	recurringService *recurring.Service
+}
+
+// NewRecurringExpenseHandler creates a new recurring expense handler
+func NewRecurringExpenseHandler(recurringService *recurring.Service) *RecurringExpenseHandler {
+	return &RecurringExpenseHandler{
+		recurringService: recurringService,
+	}
+}
+
+// Create handles POST /recurring-expenses
+func (h *RecurringExpenseHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input recurring.CreateInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	// Validate required fields
+	if input.Name == "" || input.CategoryID == 0 || input.Unit <= 0 || input.PerUnitCost <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Name, category_id, unit, and per_unit_cost are required and must be positive",
+		})
+	}
+	if !recurring.ValidFrequency(input.Frequency) || input.Interval < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "frequency must be daily, weekly, monthly or yearly and interval must be positive",
+		})
+	}
+
+	recurringExpense, err := h.recurringService.Create(userID, input)
+	if err != nil {
+		return recurringError(c, err, "Failed to create recurring expense")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    recurringExpense,
+	})
+}
+
+// GetAll handles GET /recurring-expenses
+func (h *RecurringExpenseHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	recurringExpenses, err := h.recurringService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch recurring expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    recurringExpenses,
+		"count":   len(recurringExpenses),
+	})
+}
+
+// GetByID handles GET /recurring-expenses/:id
+func (h *RecurringExpenseHandler) GetByID(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid recurring expense ID",
+		})
+	}
+
+	recurringExpense, err := h.recurringService.GetByID(uint(id), userID)
+	if err != nil {
+		return recurringError(c, err, "Failed to fetch recurring expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    recurringExpense,
+	})
+}
+
+// Update handles PUT/PATCH /recurring-expenses/:id
+func (h *RecurringExpenseHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid recurring expense ID",
+		})
+	}
+
+	var input recurring.UpdateInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	// Validate supplied fields
+	if (input.Name != nil && *input.Name == "") ||
+		(input.CategoryID != nil && *input.CategoryID == 0) ||
+		(input.Unit != nil && *input.Unit <= 0) ||
+		(input.PerUnitCost != nil && *input.PerUnitCost <= 0) ||
+		(input.Frequency != nil && !recurring.ValidFrequency(*input.Frequency)) ||
+		(input.Interval != nil && *input.Interval <= 0) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid fields: name and category_id cannot be empty, amounts and interval must be positive, frequency must be daily, weekly, monthly or yearly",
+		})
+	}
+
+	recurringExpense, err := h.recurringService.Update(uint(id), userID, input)
+	if err != nil {
+		return recurringError(c, err, "Failed to update recurring expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    recurringExpense,
+	})
+}
+
+// Delete handles DELETE /recurring-expenses/:id
+func (h *RecurringExpenseHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid recurring expense ID",
+		})
+	}
+
+	if err := h.recurringService.Delete(uint(id), userID); err != nil {
+		return recurringError(c, err, "Failed to delete recurring expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recurring expense deleted successfully",
+	})
+}
+
+// recurringError maps recurring expense service errors to HTTP responses
+func recurringError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "recurring expense not found", "category not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "end_date must be after start_date", "invalid currency":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+)
+
+// RevisionHandler handles HTTP requests for the expense change history
+type RevisionHandler struct {
+	revisionService *revision.Service
+}
+
+// NewRevisionHandler creates a new revision handler
+func NewRevisionHandler(revisionService *revision.Service) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// GetExpenseHistory handles GET /expenses/:id/history
+func (h *RevisionHandler) GetExpenseHistory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	revisions, err := h.revisionService.GetByExpense(uint(id), userID)
+	if err != nil {
+		if err.Error() == "expense not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch expense history",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    revisions,
+		"count":   len(revisions),
+	})
+}
+
+// Search handles GET /expense-revisions (admin only)
+// Optional filters: user_id, expense_id, actor_id, action, start_date and
+// end_date (YYYY-MM-DD), paginated with limit and offset.
+func (h *RevisionHandler) Search(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var query revision.Query
+	for _, filter := range []struct {
+		param  string
+		target *uint
+	}{
+		{"user_id", &query.UserID},
+		{"expense_id", &query.ExpenseID},
+		{"actor_id", &query.ActorID},
+	} {
+		if value := c.Query(filter.param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   "Invalid " + filter.param,
+				})
+			}
+			*filter.target = uint(id)
+		}
+	}
+
+	if action := c.Query("action"); action != "" {
+		if !revision.ValidAction(action) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid action (use create, update, delete, restore or purge)",
+			})
+		}
+		query.Action = action
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid start_date format (use YYYY-MM-DD)",
+			})
+		}
+		query.StartDate = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid end_date format (use YYYY-MM-DD)",
+			})
+		}
+		// Include the whole end day
+		endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+		query.EndDate = &endDate
+	}
+
+	revisions, total, err := h.revisionService.Search(query, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch expense revisions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    revisions,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
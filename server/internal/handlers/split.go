@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/split"
+)
+
+// SplitHandler handles HTTP requests for shared expenses and settle-ups
+type SplitHandler struct {
+	splitService *split.Service
+}
+
+// NewSplitHandler creates a new split handler
+func NewSplitHandler(splitService *split.Service) *SplitHandler {
+	return &SplitHandler{
+		splitService: splitService,
+	}
+}
+
+// Share handles PUT /expenses/:id/split
+func (h *SplitHandler) Share(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	var input split.SplitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	expense, err := h.splitService.Share(uint(id), userID, input)
+	if err != nil {
+		return splitError(c, err, "Failed to share expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    expense,
+	})
+}
+
+// Unshare handles DELETE /expenses/:id/split
+func (h *SplitHandler) Unshare(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	if err := h.splitService.Unshare(uint(id), userID); err != nil {
+		return splitError(c, err, "Failed to remove split")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Split removed successfully",
+	})
+}
+
+// GetShared handles GET /shared-expenses
+func (h *SplitHandler) GetShared(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenses, err := h.splitService.GetShared(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch shared expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    expenses,
+		"count":   len(expenses),
+	})
+}
+
+// GetBalances handles GET /balances
+func (h *SplitHandler) GetBalances(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	balances, err := h.splitService.GetBalances(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to calculate balances",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    balances,
+		"count":   len(balances),
+	})
+}
+
+// GetSettlements handles GET /settlements
+func (h *SplitHandler) GetSettlements(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	settlements, err := h.splitService.GetSettlements(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch settlements",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    settlements,
+		"count":   len(settlements),
+	})
+}
+
+// CreateSettlement handles POST /settlements
+func (h *SplitHandler) CreateSettlement(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input split.SettlementInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	settlement, err := h.splitService.CreateSettlement(userID, input)
+	if err != nil {
+		return splitError(c, err, "Failed to record settlement")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    settlement,
+	})
+}
+
+// DeleteSettlement handles DELETE /settlements/:id
+func (h *SplitHandler) DeleteSettlement(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid settlement ID",
+		})
+	}
+
+	if err := h.splitService.DeleteSettlement(uint(id), userID); err != nil {
+		return splitError(c, err, "Failed to delete settlement")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Settlement deleted successfully",
+	})
+}
+
+// GetContacts handles GET /contacts
+func (h *SplitHandler) GetContacts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	contacts, err := h.splitService.GetContacts(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch contacts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    contacts,
+		"count":   len(contacts),
+	})
+}
+
+// RequestContact handles POST /contacts
+func (h *SplitHandler) RequestContact(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.splitService.RequestContact(userID, input.Email); err != nil {
+		return splitError(c, err, "Failed to send contact request")
+	}
+
+	// The same response whether or not the email belongs to a user
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "If the email belongs to a user, they have been asked to become your contact",
+	})
+}
+
+// AcceptContact handles POST /contacts/:id/accept
+func (h *SplitHandler) AcceptContact(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid contact ID",
+		})
+	}
+
+	if err := h.splitService.AcceptContact(uint(id), userID); err != nil {
+		return splitError(c, err, "Failed to accept contact request")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contact request accepted",
+	})
+}
+
+// DeleteContact handles DELETE /contacts/:id
+func (h *SplitHandler) DeleteContact(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid contact ID",
+		})
+	}
+
+	if err := h.splitService.DeleteContact(uint(id), userID); err != nil {
+		return splitError(c, err, "Failed to remove contact")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contact removed successfully",
+	})
+}
+
+// splitError maps split service errors to HTTP responses
+func splitError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "expense not found", "settlement not found", "contact not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid split method":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "method must be equal, percentage or exact",
+		})
+	case "split needs at least one other participant", "participant needs a user_id or email",
+		"duplicate participant", "user is not one of your contacts", "percentages must be positive",
+		"percentages can have at most two decimals", "percentages must add up to 100",
+		"split amounts must be positive", "split amounts must add up to the expense total",
+		"cannot settle with yourself", "amount must be positive", "invalid currency",
+		"email is required", "cannot add yourself as a contact":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
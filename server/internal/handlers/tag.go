@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/tag"
+)
+
+// TagHandler handles HTTP requests for tags
+type TagHandler struct {
+	tagService *tag.Service
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService *tag.Service) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// GetAll handles GET /tags
+func (h *TagHandler) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	tags, err := h.tagService.GetByUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch tags",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    tags,
+		"count":   len(tags),
+	})
+}
+
+// Create handles POST /tags
+func (h *TagHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input tag.TagInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	created, err := h.tagService.Create(userID, input)
+	if err != nil {
+		return tagError(c, err, "Failed to create tag")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    created,
+	})
+}
+
+// Update handles PUT /tags/:id
+func (h *TagHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid tag ID",
+		})
+	}
+
+	var input tag.TagInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	updated, err := h.tagService.Update(uint(id), userID, input)
+	if err != nil {
+		return tagError(c, err, "Failed to update tag")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// Delete handles DELETE /tags/:id
+func (h *TagHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid tag ID",
+		})
+	}
+
+	if err := h.tagService.Delete(uint(id), userID); err != nil {
+		return tagError(c, err, "Failed to delete tag")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Tag deleted successfully",
+	})
+}
+
+// tagError maps tag service errors to HTTP responses
+func tagError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "tag not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "tag already exists":
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "invalid tag name":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid tag name (1-50 characters, no commas)",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
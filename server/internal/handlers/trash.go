@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/trash"
+)
+
+// TrashHandler handles HTTP requests for deleted expenses and categories
+type TrashHandler struct {
+	trashService *trash.Service
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *trash.Service) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// GetExpenses handles GET /expenses/trash
+func (h *TrashHandler) GetExpenses(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	expenses, err := h.trashService.GetExpenses(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch deleted expenses",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    expenses,
+		"count":   len(expenses),
+	})
+}
+
+// RestoreExpense handles POST /expenses/:id/restore
+func (h *TrashHandler) RestoreExpense(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	expense, err := h.trashService.RestoreExpense(uint(id), userID)
+	if err != nil {
+		return trashError(c, err, "Failed to restore expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    expense,
+	})
+}
+
+// PurgeExpense handles DELETE /expenses/trash/:id
+func (h *TrashHandler) PurgeExpense(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid expense ID",
+		})
+	}
+
+	if err := h.trashService.PurgeExpense(c.UserContext(), uint(id), userID); err != nil {
+		return trashError(c, err, "Failed to purge expense")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Expense permanently deleted",
+	})
+}
+
+// EmptyExpenses handles DELETE /expenses/trash
+func (h *TrashHandler) EmptyExpenses(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	purged, err := h.trashService.EmptyExpenses(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to empty trash",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"purged":  purged,
+	})
+}
+
+// GetCategories handles GET /categories/trash
+func (h *TrashHandler) GetCategories(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	categories, err := h.trashService.GetCategories(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch deleted categories",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    categories,
+		"count":   len(categories),
+	})
+}
+
+// RestoreCategory handles POST /categories/:id/restore
+func (h *TrashHandler) RestoreCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid category ID",
+		})
+	}
+
+	category, err := h.trashService.RestoreCategory(uint(id), userID)
+	if err != nil {
+		return trashError(c, err, "Failed to restore category")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    category,
+	})
+}
+
+// PurgeCategory handles DELETE /categories/trash/:id
+func (h *TrashHandler) PurgeCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid category ID",
+		})
+	}
+
+	if err := h.trashService.PurgeCategory(uint(id), userID); err != nil {
+		return trashError(c, err, "Failed to purge category")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Category permanently deleted",
+	})
+}
+
+// trashError maps trash service errors to HTTP responses
+func trashError(c *fiber.Ctx, err error, fallback string) error {
+	switch err.Error() {
+	case "expense not found in trash", "category not found in trash":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case "category is still in use":
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fallback,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/pagination"
 	"github.com/parvejmia9/minflow/server/internal/services/user"
 )
 
@@ -28,6 +29,40 @@ func (h *UserHandler) GetAll(c *fiber.Ctx) error {
 	if limit > 100 {
 		limit = 100
 	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// Cursor mode is selected by passing a cursor, or pagination=cursor for the first page
+	cursorParam := c.Query("cursor")
+	if cursorParam != "" || c.Query("pagination") == "cursor" {
+		var cursor *pagination.Cursor
+		if cursorParam != "" {
+			var err error
+			cursor, err = pagination.Decode(cursorParam)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   "Invalid cursor",
+				})
+			}
+		}
+
+		users, nextCursor, err := h.userService.GetAllAfter(limit, cursor)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to fetch users",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"data":        users,
+			"limit":       limit,
+			"next_cursor": nextCursor,
+		})
+	}
 
 	users, total, err := h.userService.GetAll(limit, offset)
 	if err != nil {
@@ -156,3 +191,44 @@ func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 		"data":    user,
 	})
 }
+
+// UpdateMe handles PATCH /users/me (updates the current user's settings)
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		BaseCurrency *string `json:"base_currency"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if input.BaseCurrency == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "base_currency is required",
+		})
+	}
+
+	user, err := h.userService.UpdateBaseCurrency(userID, *input.BaseCurrency)
+	if err != nil {
+		if err.Error() == "invalid currency" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid base_currency (use a 3-letter ISO 4217 code)",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update user",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    user,
+	})
+}
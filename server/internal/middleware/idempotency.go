@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/services/idempotency"
+)
+
+// Idempotency makes a route safe to retry: when a request carries an
+// Idempotency-Key header, its response is stored and replayed for retries
+// with the same key and body. Reusing a key for a different request is
+// rejected with 422. Requests without the header pass through. Must run
+// after AuthMiddleware, as keys are scoped per user.
+func Idempotency(idempotencyService *idempotency.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get("Idempotency-Key"))
+		if key == "" {
+			return c.Next()
+		}
+
+		userID := c.Locals("userID").(uint)
+		requestHash := idempotency.RequestHash(c.Method(), c.OriginalURL(), c.Body())
+
+		stored, err := idempotencyService.Claim(userID, key, requestHash)
+		if err != nil {
+			switch err.Error() {
+			case "idempotency key was used for a different request":
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"success": false,
+					"error":   "Idempotency-Key was already used for a different request",
+				})
+			case "request with this idempotency key is in progress":
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"success": false,
+					"error":   "A request with this Idempotency-Key is still in progress",
+				})
+			case "idempotency key is too long":
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"error":   "Idempotency-Key must be at most 255 characters",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to check Idempotency-Key",
+			})
+		}
+
+		// A retry of a completed request gets the original response
+		if stored != nil {
+			c.Set("Idempotent-Replayed", "true")
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			return c.Status(stored.StatusCode).Send(stored.ResponseBody)
+		}
+
+		release := func() {
+			if err := idempotencyService.Release(userID, key); err != nil {
+				log.Printf("idempotency: failed to release key for user %d: %v", userID, err)
+			}
+		}
+
+		// A panicking handler must not leave the key in progress
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			release()
+			return err
+		}
+
+		// Server errors are not stored so the request can be retried
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			release()
+			return nil
+		}
+		body := append([]byte(nil), c.Response().Body()...)
+		err = idempotencyService.Complete(userID, key, status, string(c.Response().Header.ContentType()), body)
+		if err != nil {
+			log.Printf("idempotency: failed to store response for user %d: %v", userID, err)
+		}
+
+		return nil
+	}
+}
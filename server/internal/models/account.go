@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+// Account types
+const (
+	AccountCash   = "cash"
+	AccountBank   = "bank"
+	AccountCard   = "card"
+	AccountWallet = "wallet"
+)
+
+// Account is a place money is paid from, such as a wallet, bank account or
+// credit card. Its balance is the opening balance plus incomes and incoming
+// transfers, minus outgoing transfers and the expenses paid from it.
+type Account struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	UserID         uint         `gorm:"not null;index" json:"user_id"`
+	Name           string       `gorm:"size:100;not null" json:"name"`
+	Type           string       `gorm:"size:20;not null" json:"type"`
+	Currency       string       `gorm:"size:3;not null" json:"currency"` // ISO 4217 code, expenses and incomes booked to the account must use it
+	OpeningBalance money.Amount `gorm:"not null;type:decimal(12,2);default:0" json:"opening_balance"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// Transfer moves money between two accounts of the same user. ToAmount is
+// what arrives in the destination account, which differs from Amount when
+// the accounts use different currencies.
+type Transfer struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	UserID        uint         `gorm:"not null;index" json:"user_id"`
+	FromAccountID uint         `gorm:"not null;index" json:"from_account_id"`
+	FromAccount   Account      `gorm:"foreignKey:FromAccountID" json:"from_account,omitempty"`
+	ToAccountID   uint         `gorm:"not null;index" json:"to_account_id"`
+	ToAccount     Account      `gorm:"foreignKey:ToAccountID" json:"to_account,omitempty"`
+	Amount        money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	ToAmount      money.Amount `gorm:"not null;type:decimal(12,2)" json:"to_amount"`
+	Date          time.Time    `gorm:"not null" json:"date"`
+	Note          string       `gorm:"size:255" json:"note"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+// Budget periods
+const (
+	BudgetWeekly  = "weekly"
+	BudgetMonthly = "monthly"
+	BudgetCustom  = "custom"
+)
+
+// Budget limits a user's spending per period, either in one category or
+// overall when CategoryID is nil. Amount is in the user's base currency.
+// Weekly budgets run in 7-day windows from StartDate, monthly budgets in
+// calendar months (the first one starting at StartDate) and custom budgets
+// from StartDate to EndDate. With Rollover, what was left (or overspent) in earlier
+// periods carries over into the current one.
+type Budget struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	UserID     uint         `gorm:"not null;index" json:"user_id"`
+	CategoryID *uint        `gorm:"index" json:"category_id"` // nil for all spending
+	Category   *Category    `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Amount     money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	Period     string       `gorm:"size:10;not null" json:"period"` // weekly, monthly or custom
+	StartDate  time.Time    `gorm:"not null" json:"start_date"`
+	EndDate    *time.Time   `json:"end_date,omitempty"` // required for custom budgets, otherwise optional
+	Rollover   bool         `gorm:"not null;default:false" json:"rollover"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// BudgetAlert records that spending in one period of a budget crossed a
+// threshold (a percentage of the limit). Each threshold fires once per period.
+type BudgetAlert struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	UserID      uint         `gorm:"not null;index" json:"user_id"`
+	BudgetID    uint         `gorm:"not null;uniqueIndex:idx_budget_alerts_period" json:"budget_id"`
+	Budget      *Budget      `gorm:"foreignKey:BudgetID" json:"budget,omitempty"`
+	PeriodStart time.Time    `gorm:"not null;uniqueIndex:idx_budget_alerts_period" json:"period_start"`
+	Threshold   int          `gorm:"not null;uniqueIndex:idx_budget_alerts_period" json:"threshold"`
+	Spent       money.Amount `gorm:"not null;type:decimal(12,2)" json:"spent"`
+	Limit       money.Amount `gorm:"column:limit_amount;not null;type:decimal(12,2)" json:"limit"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ReadAt      *time.Time   `json:"read_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Contact statuses
+const (
+	ContactPending  = "pending"
+	ContactAccepted = "accepted"
+)
+
+// Contact connects two users who may share expenses and settle up with
+// each other. UserID asked to connect, and the contact can be used once
+// ContactID has accepted.
+type Contact struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_contacts_pair" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ContactID uint      `gorm:"not null;uniqueIndex:idx_contacts_pair;index" json:"contact_id"`
+	Contact   User      `gorm:"foreignKey:ContactID" json:"contact,omitempty"`
+	Status    string    `gorm:"size:20;not null;default:'pending'" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
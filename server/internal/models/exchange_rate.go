@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DefaultCurrency is used for users and expenses that predate currency support
+const DefaultCurrency = "USD"
+
+// ExchangeRate is the value of one unit of FromCurrency in ToCurrency on a day
+type ExchangeRate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	FromCurrency string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date" json:"from_currency"`
+	ToCurrency   string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date" json:"to_currency"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_exchange_rates_pair_date" json:"date"`
+	Rate         float64   `gorm:"not null;type:decimal(20,10)" json:"rate"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
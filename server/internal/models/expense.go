@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/parvejmia9/minflow/server/internal/money"
 	"gorm.io/gorm"
 )
 
@@ -12,19 +13,31 @@ type Expense struct {
 	Name        string         `gorm:"not null" json:"name"`
 	CategoryID  uint           `gorm:"not null" json:"category_id"`
 	Category    Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	UserID      uint           `gorm:"not null" json:"user_id"`
+	UserID      uint           `gorm:"not null;uniqueIndex:idx_expenses_user_external" json:"user_id"`
 	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Unit        float64        `gorm:"not null" json:"unit"`
-	PerUnitCost float64        `gorm:"not null;type:decimal(10,2)" json:"per_unit_cost"`
-	Total       float64        `gorm:"not null;type:decimal(10,2)" json:"total"`
+	PerUnitCost money.Amount   `gorm:"not null;type:decimal(12,2)" json:"per_unit_cost"`
+	Total       money.Amount   `gorm:"not null;type:decimal(12,2)" json:"total"`
+	Currency    string         `gorm:"size:3;not null;default:'USD'" json:"currency"` // ISO 4217 code
 	ExpenseDate time.Time      `gorm:"not null" json:"expense_date"`
+	ExternalID  *string        `gorm:"size:255;uniqueIndex:idx_expenses_user_external" json:"external_id,omitempty"` // e.g. bank FITID, prevents re-imports
+	AccountID   *uint          `gorm:"index" json:"account_id,omitempty"`
+	Account     *Account       `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	MerchantID  *uint          `gorm:"index" json:"merchant_id,omitempty"`
+	Merchant    *Merchant      `gorm:"foreignKey:MerchantID" json:"merchant,omitempty"`
+	RawMerchant *string        `gorm:"size:255" json:"raw_merchant,omitempty"` // merchant as entered or imported, before normalization
+	Notes       string         `gorm:"type:text" json:"notes"`
+	Tags        []Tag          `gorm:"many2many:expense_tags" json:"tags,omitempty"`
+	Receipts    []Receipt      `gorm:"foreignKey:ExpenseID" json:"receipts,omitempty"`
+	Splits      []ExpenseSplit `gorm:"foreignKey:ExpenseID" json:"splits,omitempty"` // set when the expense is shared
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// BeforeSave hook to calculate total automatically
+// BeforeSave hook to calculate total automatically (exactly, rounded half
+// away from zero to the cent)
 func (e *Expense) BeforeSave(tx *gorm.DB) error {
-	e.Total = e.Unit * e.PerUnitCost
+	e.Total = e.PerUnitCost.Mul(e.Unit)
 	return nil
 }
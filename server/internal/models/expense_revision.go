@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Revision actions
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionPurge   = "purge"
+)
+
+// ExpenseRevision records one change to an expense with the state before and
+// after it. Revisions are kept after the expense is purged.
+type ExpenseRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ExpenseID uint      `gorm:"not null;index" json:"expense_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`  // owner of the expense
+	ActorID   *uint     `gorm:"index" json:"actor_id"`          // nil for system changes (scheduler, automatic purge)
+	Action    string    `gorm:"size:20;not null" json:"action"` // create, update, delete, restore or purge
+	Before    JSON      `gorm:"type:jsonb" json:"before"`
+	After     JSON      `gorm:"type:jsonb" json:"after"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// JSON is a raw JSON document stored in a jsonb column. A nil value is NULL.
+type JSON []byte
+
+// MarshalJSON writes the document as is
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// Scan implements sql.Scanner
+func (j *JSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSON(nil), v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("cannot scan %T into models.JSON", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+// Goal is a savings target, such as putting 200,000 aside for a laptop by
+// June. Amounts are in the user's base currency; progress is the sum of
+// the goal's contributions.
+type Goal struct {
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	UserID       uint         `gorm:"not null;index" json:"user_id"`
+	Name         string       `gorm:"size:100;not null" json:"name"`
+	TargetAmount money.Amount `gorm:"not null;type:decimal(12,2)" json:"target_amount"`
+	Deadline     *time.Time   `json:"deadline,omitempty"`
+	Note         string       `gorm:"size:255" json:"note"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// GoalContribution is money put towards a goal
+type GoalContribution struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	GoalID    uint         `gorm:"not null;index" json:"goal_id"`
+	UserID    uint         `gorm:"not null;index" json:"user_id"`
+	Amount    money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	Date      time.Time    `gorm:"not null" json:"date"`
+	Note      string       `gorm:"size:255" json:"note"`
+	CreatedAt time.Time    `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+// Income is money a user received, such as a salary payment. Recurring marks
+// regular income, e.g. to tell salaries from one-off payments.
+type Income struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	UserID    uint         `gorm:"not null;index" json:"user_id"`
+	Source    string       `gorm:"size:100;not null" json:"source"`
+	Amount    money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	Currency  string       `gorm:"size:3;not null" json:"currency"` // ISO 4217 code
+	Date      time.Time    `gorm:"not null;index" json:"date"`
+	Recurring bool         `gorm:"not null;default:false" json:"recurring"`
+	AccountID *uint        `gorm:"index" json:"account_id,omitempty"` // account the income was paid into
+	Account   *Account     `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Note      string       `gorm:"size:255" json:"note"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
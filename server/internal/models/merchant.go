@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Merchant is a normalized business name expenses can be linked to, e.g.
+// "Amazon" for a raw "AMZN Mktp US*2X". Names are unique per user.
+type Merchant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_merchants_user_name" json:"name"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_merchants_user_name" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Alias match types, tried in this order
+const (
+	AliasExact    = "exact"
+	AliasPrefix   = "prefix"
+	AliasContains = "contains"
+	AliasRegex    = "regex"
+)
+
+// MerchantAlias is a user-defined rule mapping raw merchant strings to a
+// merchant. Patterns are matched case-insensitively.
+type MerchantAlias struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	MerchantID uint      `gorm:"not null;index" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"foreignKey:MerchantID" json:"merchant,omitempty"`
+	Pattern    string    `gorm:"size:255;not null" json:"pattern"`
+	MatchType  string    `gorm:"size:10;not null;default:'contains'" json:"match_type"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Receipt is an image or PDF attached to an expense as proof of purchase.
+// The file itself lives in the configured storage backend under StorageKey.
+type Receipt struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ExpenseID   uint      `gorm:"not null;index" json:"expense_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"size:512;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
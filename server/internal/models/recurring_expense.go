@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"gorm.io/gorm"
+)
+
+// Recurrence frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// RecurringExpense is a template that is turned into an Expense on every
+// occurrence of its schedule (every Interval days/weeks/months/years from
+// StartDate, until EndDate if set)
+type RecurringExpense struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	CategoryID  uint           `gorm:"not null" json:"category_id"`
+	Category    Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	Unit        float64        `gorm:"not null" json:"unit"`
+	PerUnitCost money.Amount   `gorm:"not null;type:decimal(12,2)" json:"per_unit_cost"`
+	Currency    string         `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	Frequency   string         `gorm:"size:20;not null" json:"frequency"`
+	Interval    int            `gorm:"not null;default:1" json:"interval"`
+	StartDate   time.Time      `gorm:"not null" json:"start_date"`
+	EndDate     *time.Time     `json:"end_date"`
+	Occurrences int            `gorm:"not null;default:0" json:"occurrences"` // number of occurrences already posted
+	NextRunDate *time.Time     `gorm:"index" json:"next_run_date"`            // nil once the schedule has ended
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// OccurrenceDate returns the date of the n-th (zero-based) occurrence.
+// Monthly and yearly schedules are clamped to the end of shorter months,
+// so a schedule starting on Jan 31 falls on Feb 28/29.
+func (r *RecurringExpense) OccurrenceDate(n int) time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	steps := n * interval
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		return r.StartDate.AddDate(0, 0, steps)
+	case FrequencyWeekly:
+		return r.StartDate.AddDate(0, 0, 7*steps)
+	case FrequencyYearly:
+		steps *= 12
+	}
+
+	// Add months without overflowing into the following month
+	start := r.StartDate
+	firstOfMonth := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	target := firstOfMonth.AddDate(0, steps, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := start.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+// NextOccurrence returns the next occurrence that has not been posted yet,
+// or nil when the schedule has ended
+func (r *RecurringExpense) NextOccurrence() *time.Time {
+	next := r.OccurrenceDate(r.Occurrences)
+	if r.EndDate != nil && next.After(*r.EndDate) {
+		return nil
+	}
+	return &next
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestOccurrenceDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		interval  int
+		start     time.Time
+		n         int
+		want      time.Time
+	}{
+		{"first occurrence is the start", FrequencyMonthly, 1, date(2026, 1, 15), 0, date(2026, 1, 15)},
+		{"daily", FrequencyDaily, 1, date(2026, 2, 27), 3, date(2026, 3, 2)},
+		{"every 3 days", FrequencyDaily, 3, date(2026, 1, 1), 2, date(2026, 1, 7)},
+		{"weekly", FrequencyWeekly, 1, date(2026, 1, 5), 4, date(2026, 2, 2)},
+		{"fortnightly", FrequencyWeekly, 2, date(2026, 1, 5), 1, date(2026, 1, 19)},
+		{"monthly", FrequencyMonthly, 1, date(2026, 1, 10), 13, date(2027, 2, 10)},
+		{"month end clamps to february", FrequencyMonthly, 1, date(2026, 1, 31), 1, date(2026, 2, 28)},
+		{"month end clamps to leap february", FrequencyMonthly, 1, date(2028, 1, 31), 1, date(2028, 2, 29)},
+		{"month end returns after short month", FrequencyMonthly, 1, date(2026, 1, 31), 2, date(2026, 3, 31)},
+		{"quarterly", FrequencyMonthly, 3, date(2026, 11, 30), 1, date(2027, 2, 28)},
+		{"yearly", FrequencyYearly, 1, date(2026, 6, 1), 2, date(2028, 6, 1)},
+		{"yearly leap day", FrequencyYearly, 1, date(2028, 2, 29), 1, date(2029, 2, 28)},
+		{"zero interval counts as one", FrequencyDaily, 0, date(2026, 1, 1), 5, date(2026, 1, 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RecurringExpense{Frequency: tt.frequency, Interval: tt.interval, StartDate: tt.start}
+			if got := r.OccurrenceDate(tt.n); !got.Equal(tt.want) {
+				t.Errorf("OccurrenceDate(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	end := date(2026, 3, 1)
+	r := &RecurringExpense{Frequency: FrequencyMonthly, Interval: 1, StartDate: date(2026, 1, 1), EndDate: &end}
+
+	for occurrences, want := range []time.Time{date(2026, 1, 1), date(2026, 2, 1), date(2026, 3, 1)} {
+		r.Occurrences = occurrences
+		if got := r.NextOccurrence(); got == nil || !got.Equal(want) {
+			t.Errorf("NextOccurrence() after %d = %v, want %v", occurrences, got, want)
+		}
+	}
+
+	r.Occurrences = 3
+	if got := r.NextOccurrence(); got != nil {
+		t.Errorf("NextOccurrence() after the end date = %v, want nil", got)
+	}
+}
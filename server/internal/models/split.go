@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+// Split methods
+const (
+	SplitEqual      = "equal"
+	SplitPercentage = "percentage"
+	SplitExact      = "exact"
+)
+
+// ExpenseSplit is one participant's share of a shared expense. The user who
+// recorded the expense paid for it, so every other participant owes them
+// their Amount (in the expense's currency).
+type ExpenseSplit struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	ExpenseID uint         `gorm:"not null;uniqueIndex:idx_expense_splits_expense_user" json:"expense_id"`
+	UserID    uint         `gorm:"not null;uniqueIndex:idx_expense_splits_expense_user;index" json:"user_id"`
+	User      User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Method    string       `gorm:"size:20;not null" json:"method"`
+	Shares    int64        `gorm:"not null;default:0" json:"shares"` // 1 for equal, hundredths of a percent, or the exact amount in minor units; used to rebalance when the total changes
+	Amount    money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Settlement records a payment from one user to another that settles
+// (part of) what they owe from shared expenses
+type Settlement struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	FromUserID uint         `gorm:"not null;index" json:"from_user_id"`
+	FromUser   User         `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUserID   uint         `gorm:"not null;index" json:"to_user_id"`
+	ToUser     User         `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+	Amount     money.Amount `gorm:"not null;type:decimal(12,2)" json:"amount"`
+	Currency   string       `gorm:"size:3;not null" json:"currency"`
+	Date       time.Time    `gorm:"not null" json:"date"`
+	Note       string       `gorm:"size:255" json:"note"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
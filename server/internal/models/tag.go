@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a free-form label a user can put on any number of expenses.
+// Names are stored normalized (trimmed, lower-case) and are unique per user.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
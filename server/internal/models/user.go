@@ -8,12 +8,13 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Email     string         `gorm:"unique;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"` // "-" means don't return in JSON
-	Name      string         `json:"name"`
-	IsAdmin   bool           `gorm:"default:false" json:"is_admin"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Email        string         `gorm:"unique;not null" json:"email"`
+	Password     string         `gorm:"not null" json:"-"` // "-" means don't return in JSON
+	Name         string         `json:"name"`
+	IsAdmin      bool           `gorm:"default:false" json:"is_admin"`
+	BaseCurrency string         `gorm:"size:3;not null;default:'USD'" json:"base_currency"` // analytics are converted into this currency
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
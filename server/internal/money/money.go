@@ -0,0 +1,191 @@
+// Package money provides an exact monetary amount type.
+//
+// Amounts are stored as integer minor units (cents, two decimal places), so
+// arithmetic never drifts the way float64 does. Whenever a result has more
+// than two decimals it is rounded half away from zero, which matches
+// PostgreSQL's ROUND() on numeric values.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places of an Amount
+const Scale = 2
+
+// Amount is a monetary amount in minor units (hundredths)
+type Amount int64
+
+var (
+	hundred  = big.NewInt(100)
+	maxInt64 = big.NewInt(math.MaxInt64)
+	minInt64 = big.NewInt(math.MinInt64)
+)
+
+// ErrInvalid is returned when a value cannot be read as an amount
+var ErrInvalid = errors.New("invalid amount")
+
+// Parse reads a decimal string such as "12", "-3.5" or "0.125" exactly,
+// rounding to two decimals half away from zero
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/") {
+		return 0, ErrInvalid
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, ErrInvalid
+	}
+	return fromRat(r)
+}
+
+// FromFloat converts a float using its shortest decimal representation, so
+// FromFloat(0.1) is exactly 0.10
+func FromFloat(f float64) (Amount, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ErrInvalid
+	}
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// FromMinor creates an amount from minor units
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+// Minor returns the amount in minor units
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// Mul multiplies the amount by a quantity (e.g. a unit count) exactly and
+// rounds the result half away from zero
+func (a Amount) Mul(quantity float64) Amount {
+	q, ok := new(big.Rat).SetString(strconv.FormatFloat(quantity, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	r := new(big.Rat).Mul(q, big.NewRat(int64(a), 100))
+	result, err := fromRat(r)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// Div divides the amount by a divisor and rounds the result half away from zero
+func (a Amount) Div(divisor float64) Amount {
+	d, ok := new(big.Rat).SetString(strconv.FormatFloat(divisor, 'f', -1, 64))
+	if !ok || d.Sign() == 0 {
+		return 0
+	}
+	r := new(big.Rat).Quo(big.NewRat(int64(a), 100), d)
+	result, err := fromRat(r)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// Abs returns the absolute value of the amount
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Float64 returns the amount in major units. Only use it for display.
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// String formats the amount with exactly two decimals, e.g. "-12.50"
+func (a Amount) String() string {
+	minor := int64(a)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(minor))
+	whole, frac := new(big.Int).QuoRem(abs, hundred, new(big.Int))
+	return fmt.Sprintf("%s%s.%02d", sign, whole.String(), frac.Int64())
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string without going
+// through float64
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	text = strings.Trim(text, `"`)
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for numeric columns
+func (a *Amount) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case string:
+		*a, err = Parse(v)
+	case []byte:
+		*a, err = Parse(string(v))
+	case int64:
+		*a, err = fromRat(new(big.Rat).SetInt64(v))
+	case float64:
+		*a, err = FromFloat(v)
+	default:
+		err = fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+	return err
+}
+
+// Value implements driver.Valuer, writing the exact decimal text
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// fromRat converts a value in major units to minor units, rounding half
+// away from zero
+func fromRat(r *big.Rat) (Amount, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(hundred))
+
+	num := new(big.Int).Set(scaled.Num())
+	den := scaled.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// |remainder| * 2 >= denominator means the fraction is at least one half
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	if quotient.Cmp(maxInt64) > 0 || quotient.Cmp(minInt64) < 0 {
+		return 0, ErrInvalid
+	}
+	return Amount(quotient.Int64()), nil
+}
@@ -0,0 +1,193 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{"12", 1200, false},
+		{"12.5", 1250, false},
+		{"12.50", 1250, false},
+		{" 0.01 ", 1, false},
+		{"-3.5", -350, false},
+		{"+7", 700, false},
+		{".5", 50, false},
+		// More than two decimals round half away from zero
+		{"0.125", 13, false},
+		{"0.124", 12, false},
+		{"-0.125", -13, false},
+		{"-0.124", -12, false},
+		{"2.675", 268, false},
+		{"0.005", 1, false},
+		{"-0.005", -1, false},
+		{"0.0049999", 0, false},
+		{"1e2", 10000, false},
+		{"92233720368547758.07", math.MaxInt64, false},
+		{"92233720368547758.08", 0, true},
+		{"", 0, true},
+		{"  ", 0, true},
+		{"abc", 0, true},
+		{"1/3", 0, true},
+		{"1,50", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Parse(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		in      float64
+		want    Amount
+		wantErr bool
+	}{
+		{0.1, 10, false},
+		{0.1 + 0.2, 30, false},
+		{19.99, 1999, false},
+		{-4.005, -401, false},
+		{math.NaN(), 0, true},
+		{math.Inf(1), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := FromFloat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FromFloat(%v) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		quantity float64
+		want     Amount
+	}{
+		{10, 3, 30},           // 0.10 * 3 is exactly 0.30, unlike float64
+		{1999, 2, 3998},       // 19.99 * 2
+		{333, 1.5, 500},       // 3.33 * 1.5 = 4.995 rounds up
+		{-333, 1.5, -500},     // and away from zero when negative
+		{335, 0.5, 168},       // 1.675
+		{1000, 0.333, 333},    // 3.33
+		{1250, 0, 0},          // 0
+		{100, -2, -200},       // negative quantity
+		{1, 0.4999, 0},        // 0.004999
+		{12345, 1.001, 12357}, // 123.57345
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Mul(tt.quantity); got != tt.want {
+			t.Errorf("%s.Mul(%v) = %s, want %s", tt.amount, tt.quantity, got, tt.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		amount  Amount
+		divisor float64
+		want    Amount
+	}{
+		{1000, 4, 250},
+		{1000, 3, 333},    // 3.333...
+		{2000, 3, 667},    // 6.666...
+		{-2000, 3, -667},  // away from zero
+		{1, 2, 1},         // 0.005 rounds up
+		{-1, 2, -1},       // -0.005 rounds down
+		{1000, 0.5, 2000}, // dividing by a fraction
+		{1000, -4, -250},  // negative divisor
+		{1000, 0, 0},      // division by zero yields zero
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Div(tt.divisor); got != tt.want {
+			t.Errorf("%s.Div(%v) = %s, want %s", tt.amount, tt.divisor, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{1250, "12.50"},
+		{-123456, "-1234.56"},
+		{math.MaxInt64, "92233720368547758.07"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.amount), got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var got struct {
+		A Amount  `json:"a"`
+		B Amount  `json:"b"`
+		C *Amount `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 0.1, "b": "12.345", "c": null}`), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.A != 10 || got.B != 1235 || got.C != nil {
+		t.Errorf("Unmarshal() = %+v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": "ten"}`), &got); err == nil {
+		t.Error("Unmarshal() of a non-number should fail")
+	}
+
+	data, err := json.Marshal(map[string]Amount{"total": -1205})
+	if err != nil || string(data) != `{"total":-12.05}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    Amount
+		wantErr bool
+	}{
+		{"12.50", 1250, false},
+		{[]byte("-0.07"), -7, false},
+		{int64(3), 300, false},
+		{0.3, 30, false},
+		{nil, 0, false},
+		{true, 0, true},
+		{"x", 0, true},
+	}
+
+	for _, tt := range tests {
+		var got Amount = 99
+		err := got.Scan(tt.src)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("Scan(%v) = %d, %v; want %d, error %v", tt.src, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	value, err := Amount(-1250).Value()
+	if err != nil || value != "-12.50" {
+		t.Errorf("Value() = %v, %v", value, err)
+	}
+}
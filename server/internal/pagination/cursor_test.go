@@ -0,0 +1,52 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"utc", Cursor{Time: time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC), ID: 42}},
+		{"sub-second", Cursor{Time: time.Date(2026, 1, 1, 0, 0, 0, 123456000, time.UTC), ID: 1}},
+		{"offset zone", Cursor{Time: time.Date(2025, 12, 31, 23, 59, 59, 0, time.FixedZone("BDT", 6*3600)), ID: 4294967295}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.ID != tt.cursor.ID || !decoded.Time.Equal(tt.cursor.Time) {
+				t.Errorf("Decode() = %+v, want %+v", *decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("nope"))},
+		{"zero id", base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2026-01-01T00:00:00Z","id":0}`))},
+		{"bad time", base64.RawURLEncoding.EncodeToString([]byte(`{"t":"yesterday","id":3}`))},
+		{"padded", base64.URLEncoding.EncodeToString([]byte(`{"t":"2026-01-01T00:00:00Z","id":3}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.input); err == nil || err.Error() != "invalid cursor" {
+				t.Errorf("Decode(%q) error = %v, want invalid cursor", tt.input, err)
+			}
+		})
+	}
+}
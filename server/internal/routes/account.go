@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupAccountRoutes(router fiber.Router, accountHandler *handlers.AccountHandler) {
+	// GET /accounts - Get all accounts of the user with current balances
+	router.Get("/accounts", accountHandler.GetAll)
+
+	// GET /accounts/:id - Get single account with its current balance
+	router.Get("/accounts/:id", accountHandler.GetByID)
+
+	// GET /accounts/:id/history - Get the daily balance of an account
+	router.Get("/accounts/:id/history", accountHandler.GetHistory)
+
+	// POST /accounts - Create account
+	router.Post("/accounts", accountHandler.Create)
+
+	// PUT /accounts/:id - Update account
+	router.Put("/accounts/:id", accountHandler.Update)
+
+	// PATCH /accounts/:id - Partially update account
+	router.Patch("/accounts/:id", accountHandler.Update)
+
+	// DELETE /accounts/:id - Delete an unused account
+	router.Delete("/accounts/:id", accountHandler.Delete)
+
+	// GET /transfers - Get transfers between the user's accounts
+	router.Get("/transfers", accountHandler.GetTransfers)
+
+	// POST /transfers - Record a transfer between two accounts
+	router.Post("/transfers", accountHandler.CreateTransfer)
+
+	// DELETE /transfers/:id - Delete transfer
+	router.Delete("/transfers/:id", accountHandler.DeleteTransfer)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupBudgetRoutes(router fiber.Router, budgetHandler *handlers.BudgetHandler) {
+	// GET /budgets - Get the user's budgets
+	router.Get("/budgets", budgetHandler.GetAll)
+
+	// POST /budgets - Create a budget
+	router.Post("/budgets", budgetHandler.Create)
+
+	// GET /budgets/status - Spent, remaining and percent of every active budget
+	router.Get("/budgets/status", budgetHandler.GetStatuses)
+
+	// GET /budgets/alerts - Get budget alerts, newest first
+	router.Get("/budgets/alerts", budgetHandler.GetAlerts)
+
+	// POST /budgets/alerts/:id/read - Mark a budget alert as read
+	router.Post("/budgets/alerts/:id/read", budgetHandler.MarkAlertRead)
+
+	// GET /budgets/:id - Get single budget
+	router.Get("/budgets/:id", budgetHandler.GetByID)
+
+	// GET /budgets/:id/status - Status of a budget in the period containing ?date=
+	router.Get("/budgets/:id/status", budgetHandler.GetStatus)
+
+	// PUT /budgets/:id - Update budget
+	router.Put("/budgets/:id", budgetHandler.Update)
+
+	// PATCH /budgets/:id - Partially update budget
+	router.Patch("/budgets/:id", budgetHandler.Update)
+
+	// DELETE /budgets/:id - Delete budget
+	router.Delete("/budgets/:id", budgetHandler.Delete)
+}
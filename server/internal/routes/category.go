@@ -14,4 +14,13 @@ func SetupCategoryRoutes(router fiber.Router, categoryHandler *handlers.Category
 
 	// POST /categories - Create new category
 	router.Post("/categories", categoryHandler.Create)
+
+	// PUT /categories/:id - Rename a user category
+	router.Put("/categories/:id", categoryHandler.Update)
+
+	// PATCH /categories/:id - Rename a user category
+	router.Patch("/categories/:id", categoryHandler.Update)
+
+	// DELETE /categories/:id - Move a user category to the trash
+	router.Delete("/categories/:id", categoryHandler.Delete)
 }
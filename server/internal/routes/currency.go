@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+	"github.com/parvejmia9/minflow/server/internal/middleware"
+)
+
+func SetupCurrencyRoutes(router fiber.Router, currencyHandler *handlers.CurrencyHandler) {
+	// GET /exchange-rates - List exchange rates (optional from/to filters)
+	router.Get("/exchange-rates", currencyHandler.GetRates)
+
+	// POST /exchange-rates - Create or replace a rate (admin only)
+	router.Post("/exchange-rates", middleware.AdminMiddleware(), currencyHandler.SetRate)
+
+	// POST /exchange-rates/import - Load rates from a CSV file (admin only)
+	router.Post("/exchange-rates/import", middleware.AdminMiddleware(), currencyHandler.ImportRates)
+
+	// DELETE /exchange-rates/:id - Delete a rate (admin only)
+	router.Delete("/exchange-rates/:id", middleware.AdminMiddleware(), currencyHandler.DeleteRate)
+}
@@ -5,10 +5,17 @@ import (
 	"github.com/parvejmia9/minflow/server/internal/handlers"
 )
 
-func SetupExpenseRoutes(router fiber.Router, expenseHandler *handlers.ExpenseHandler) {
-	// All expense routes require authentication
+func SetupExpenseRoutes(router fiber.Router, expenseHandler *handlers.ExpenseHandler, idempotent fiber.Handler) {
+	// All expense routes require authentication. Creates and updates accept
+	// an Idempotency-Key header so they can be retried safely.
 	// POST /expenses - Create new expense
-	router.Post("/expenses", expenseHandler.Create)
+	router.Post("/expenses", idempotent, expenseHandler.Create)
+
+	// POST /expenses/bulk - Create many expenses in one transaction
+	router.Post("/expenses/bulk", idempotent, expenseHandler.CreateBulk)
+
+	// POST /expenses/batch - Recategorize, delete, re-date or tag many expenses at once
+	router.Post("/expenses/batch", idempotent, expenseHandler.Batch)
 
 	// GET /expenses - Get all expenses for user (paginated)
 	router.Get("/expenses", expenseHandler.GetAll)
@@ -19,9 +26,24 @@ func SetupExpenseRoutes(router fiber.Router, expenseHandler *handlers.ExpenseHan
 	// GET /expenses/analytics - Get analytics
 	router.Get("/expenses/analytics", expenseHandler.GetAnalytics)
 
+	// GET /expenses/export - Export expenses as CSV, JSON or XLSX
+	router.Get("/expenses/export", expenseHandler.Export)
+
+	// GET /expenses/search - Full-text search with ranked, highlighted results
+	router.Get("/expenses/search", expenseHandler.Search)
+
+	// GET /expenses/duplicates - Get groups of likely duplicate expenses
+	router.Get("/expenses/duplicates", expenseHandler.GetDuplicates)
+
 	// GET /expenses/:id - Get single expense
 	router.Get("/expenses/:id", expenseHandler.GetByID)
 
+	// PUT /expenses/:id - Update expense
+	router.Put("/expenses/:id", idempotent, expenseHandler.Update)
+
+	// PATCH /expenses/:id - Partially update expense
+	router.Patch("/expenses/:id", idempotent, expenseHandler.Update)
+
 	// DELETE /expenses/:id - Delete expense
 	router.Delete("/expenses/:id", expenseHandler.Delete)
 }
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupGoalRoutes(router fiber.Router, goalHandler *handlers.GoalHandler) {
+	// GET /goals - Get the user's goals with their progress
+	router.Get("/goals", goalHandler.GetAll)
+
+	// POST /goals - Create a goal
+	router.Post("/goals", goalHandler.Create)
+
+	// GET /goals/:id - Get single goal
+	router.Get("/goals/:id", goalHandler.GetByID)
+
+	// PUT /goals/:id - Update goal
+	router.Put("/goals/:id", goalHandler.Update)
+
+	// PATCH /goals/:id - Partially update goal
+	router.Patch("/goals/:id", goalHandler.Update)
+
+	// DELETE /goals/:id - Delete goal and its contributions
+	router.Delete("/goals/:id", goalHandler.Delete)
+
+	// GET /goals/:id/contributions - Get contributions to a goal
+	router.Get("/goals/:id/contributions", goalHandler.GetContributions)
+
+	// POST /goals/:id/contributions - Record a contribution
+	router.Post("/goals/:id/contributions", goalHandler.AddContribution)
+
+	// DELETE /goals/:id/contributions/:contributionId - Delete a contribution
+	router.Delete("/goals/:id/contributions/:contributionId", goalHandler.DeleteContribution)
+
+	// GET /goals/:id/projection - Estimate the completion date from the recent savings pace
+	router.Get("/goals/:id/projection", goalHandler.GetProjection)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupImportRoutes(router fiber.Router, importHandler *handlers.ImportHandler) {
+	// POST /expenses/import/csv - Import (or preview) expenses from a CSV file
+	router.Post("/expenses/import/csv", importHandler.ImportCSV)
+
+	// POST /expenses/import/bank - Import (or preview) debits from an OFX/QFX/QIF statement
+	router.Post("/expenses/import/bank", importHandler.ImportBankStatement)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupIncomeRoutes(router fiber.Router, incomeHandler *handlers.IncomeHandler) {
+	// GET /incomes - Get the user's incomes, newest first
+	router.Get("/incomes", incomeHandler.GetAll)
+
+	// GET /incomes/:id - Get single income
+	router.Get("/incomes/:id", incomeHandler.GetByID)
+
+	// POST /incomes - Record an income
+	router.Post("/incomes", incomeHandler.Create)
+
+	// PUT /incomes/:id - Update income
+	router.Put("/incomes/:id", incomeHandler.Update)
+
+	// PATCH /incomes/:id - Partially update income
+	router.Patch("/incomes/:id", incomeHandler.Update)
+
+	// DELETE /incomes/:id - Delete income
+	router.Delete("/incomes/:id", incomeHandler.Delete)
+
+	// GET /cash-flow - Income vs. expenses, net cash flow and savings rate
+	router.Get("/cash-flow", incomeHandler.GetCashFlow)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupMerchantRoutes(router fiber.Router, merchantHandler *handlers.MerchantHandler) {
+	// GET /merchants - Get all merchants of the user with usage counts
+	router.Get("/merchants", merchantHandler.GetAll)
+
+	// GET /merchants/normalize - Preview how a raw merchant string is normalized
+	router.Get("/merchants/normalize", merchantHandler.Normalize)
+
+	// POST /merchants - Create merchant
+	router.Post("/merchants", merchantHandler.Create)
+
+	// GET /merchants/aliases - Get all alias rules in the order they are tried
+	router.Get("/merchants/aliases", merchantHandler.GetAliases)
+
+	// POST /merchants/aliases - Create alias rule
+	router.Post("/merchants/aliases", merchantHandler.CreateAlias)
+
+	// PUT /merchants/aliases/:id - Update alias rule
+	router.Put("/merchants/aliases/:id", merchantHandler.UpdateAlias)
+
+	// DELETE /merchants/aliases/:id - Delete alias rule
+	router.Delete("/merchants/aliases/:id", merchantHandler.DeleteAlias)
+
+	// PUT /merchants/:id - Rename merchant
+	router.Put("/merchants/:id", merchantHandler.Update)
+
+	// DELETE /merchants/:id - Delete merchant and unlink its expenses
+	router.Delete("/merchants/:id", merchantHandler.Delete)
+}
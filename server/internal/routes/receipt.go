@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupReceiptRoutes(router fiber.Router, receiptHandler *handlers.ReceiptHandler) {
+	// GET /expenses/:id/receipts - List receipts attached to an expense
+	router.Get("/expenses/:id/receipts", receiptHandler.GetAll)
+
+	// POST /expenses/:id/receipts - Upload a receipt (image or PDF)
+	router.Post("/expenses/:id/receipts", receiptHandler.Upload)
+
+	// GET /expenses/:id/receipts/:receiptId - Download a receipt
+	router.Get("/expenses/:id/receipts/:receiptId", receiptHandler.Download)
+
+	// DELETE /expenses/:id/receipts/:receiptId - Delete a receipt
+	router.Delete("/expenses/:id/receipts/:receiptId", receiptHandler.Delete)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupRecurringExpenseRoutes(router fiber.Router, recurringHandler *handlers.RecurringExpenseHandler) {
+	// GET /recurring-expenses - Get all recurring expenses for user
+	router.Get("/recurring-expenses", recurringHandler.GetAll)
+
+	// POST /recurring-expenses - Create recurring expense
+	router.Post("/recurring-expenses", recurringHandler.Create)
+
+	// GET /recurring-expenses/:id - Get single recurring expense
+	router.Get("/recurring-expenses/:id", recurringHandler.GetByID)
+
+	// PUT /recurring-expenses/:id - Update recurring expense
+	router.Put("/recurring-expenses/:id", recurringHandler.Update)
+
+	// PATCH /recurring-expenses/:id - Partially update recurring expense
+	router.Patch("/recurring-expenses/:id", recurringHandler.Update)
+
+	// DELETE /recurring-expenses/:id - Delete recurring expense
+	router.Delete("/recurring-expenses/:id", recurringHandler.Delete)
+}
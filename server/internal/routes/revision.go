@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+	"github.com/parvejmia9/minflow/server/internal/middleware"
+)
+
+func SetupRevisionRoutes(router fiber.Router, revisionHandler *handlers.RevisionHandler) {
+	// GET /expenses/:id/history - Get the change history of an expense
+	router.Get("/expenses/:id/history", revisionHandler.GetExpenseHistory)
+
+	// GET /expense-revisions - Search expense changes across users (admin only)
+	router.Get("/expense-revisions", middleware.AdminMiddleware(), revisionHandler.Search)
+}
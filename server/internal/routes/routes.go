@@ -5,6 +5,7 @@ import (
 	"github.com/parvejmia9/minflow/server/internal/handlers"
 	"github.com/parvejmia9/minflow/server/internal/middleware"
 	"github.com/parvejmia9/minflow/server/internal/services/auth"
+	"github.com/parvejmia9/minflow/server/internal/services/idempotency"
 )
 
 func healthCheck(c *fiber.Ctx) error {
@@ -14,11 +15,25 @@ func healthCheck(c *fiber.Ctx) error {
 func SetupRoutes(
 	app *fiber.App,
 	authService *auth.Service,
+	idempotencyService *idempotency.Service,
 	authHandler *handlers.AuthHandler,
 	categoryHandler *handlers.CategoryHandler,
 	expenseHandler *handlers.ExpenseHandler,
 	userHandler *handlers.UserHandler,
 	aiExpenseHandler *handlers.AIExpenseHandler,
+	importHandler *handlers.ImportHandler,
+	recurringHandler *handlers.RecurringExpenseHandler,
+	currencyHandler *handlers.CurrencyHandler,
+	receiptHandler *handlers.ReceiptHandler,
+	tagHandler *handlers.TagHandler,
+	splitHandler *handlers.SplitHandler,
+	trashHandler *handlers.TrashHandler,
+	revisionHandler *handlers.RevisionHandler,
+	merchantHandler *handlers.MerchantHandler,
+	accountHandler *handlers.AccountHandler,
+	incomeHandler *handlers.IncomeHandler,
+	budgetHandler *handlers.BudgetHandler,
+	goalHandler *handlers.GoalHandler,
 ) {
 	api := app.Group("/api")
 
@@ -31,11 +46,50 @@ func SetupRoutes(
 	// Protected routes (require authentication)
 	protected := api.Group("", middleware.AuthMiddleware(authService))
 
+	// Trash routes
+	SetupTrashRoutes(protected, trashHandler)
+
 	// Category routes
 	SetupCategoryRoutes(protected, categoryHandler)
 
+	// Tag routes
+	SetupTagRoutes(protected, tagHandler)
+
+	// Merchant and merchant alias routes
+	SetupMerchantRoutes(protected, merchantHandler)
+
+	// Account and transfer routes
+	SetupAccountRoutes(protected, accountHandler)
+
+	// Income and cash-flow routes
+	SetupIncomeRoutes(protected, incomeHandler)
+
+	// Budget and budget alert routes
+	SetupBudgetRoutes(protected, budgetHandler)
+
+	// Savings goal routes
+	SetupGoalRoutes(protected, goalHandler)
+
+	// Import routes
+	SetupImportRoutes(protected, importHandler)
+
 	// Expense routes
-	SetupExpenseRoutes(protected, expenseHandler)
+	SetupExpenseRoutes(protected, expenseHandler, middleware.Idempotency(idempotencyService))
+
+	// Receipt attachment routes
+	SetupReceiptRoutes(protected, receiptHandler)
+
+	// Expense history routes
+	SetupRevisionRoutes(protected, revisionHandler)
+
+	// Shared expense and settle-up routes
+	SetupSplitRoutes(protected, splitHandler)
+
+	// Recurring expense routes
+	SetupRecurringExpenseRoutes(protected, recurringHandler)
+
+	// Exchange rate routes
+	SetupCurrencyRoutes(protected, currencyHandler)
 
 	// AI Expense extraction route
 	protected.Post("/expenses/extract", aiExpenseHandler.ExtractExpenses)
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupSplitRoutes(router fiber.Router, splitHandler *handlers.SplitHandler) {
+	// PUT /expenses/:id/split - Share an expense (equal, percentage or exact split)
+	router.Put("/expenses/:id/split", splitHandler.Share)
+
+	// DELETE /expenses/:id/split - Stop sharing an expense
+	router.Delete("/expenses/:id/split", splitHandler.Unshare)
+
+	// GET /shared-expenses - Expenses the user shared or has a share in
+	router.Get("/shared-expenses", splitHandler.GetShared)
+
+	// GET /balances - Net balance with every user the user shares expenses with
+	router.Get("/balances", splitHandler.GetBalances)
+
+	// GET /settlements - Settle-up payments made or received
+	router.Get("/settlements", splitHandler.GetSettlements)
+
+	// POST /settlements - Record a settle-up payment
+	router.Post("/settlements", splitHandler.CreateSettlement)
+
+	// DELETE /settlements/:id - Delete a settle-up payment
+	router.Delete("/settlements/:id", splitHandler.DeleteSettlement)
+
+	// GET /contacts - Contacts and the contact requests awaiting an answer
+	router.Get("/contacts", splitHandler.GetContacts)
+
+	// POST /contacts - Ask a user, by email, to become a contact
+	router.Post("/contacts", splitHandler.RequestContact)
+
+	// POST /contacts/:id/accept - Accept a contact request
+	router.Post("/contacts/:id/accept", splitHandler.AcceptContact)
+
+	// DELETE /contacts/:id - Remove a contact or decline a request
+	router.Delete("/contacts/:id", splitHandler.DeleteContact)
+}
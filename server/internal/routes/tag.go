@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+func SetupTagRoutes(router fiber.Router, tagHandler *handlers.TagHandler) {
+	// GET /tags - Get all tags of the user with usage counts
+	router.Get("/tags", tagHandler.GetAll)
+
+	// POST /tags - Create tag
+	router.Post("/tags", tagHandler.Create)
+
+	// PUT /tags/:id - Rename tag
+	router.Put("/tags/:id", tagHandler.Update)
+
+	// DELETE /tags/:id - Delete tag and remove it from all expenses
+	router.Delete("/tags/:id", tagHandler.Delete)
+}
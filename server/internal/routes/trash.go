@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/parvejmia9/minflow/server/internal/handlers"
+)
+
+// SetupTrashRoutes must be registered before the expense and category
+// routes so /trash isn't matched as an :id
+func SetupTrashRoutes(router fiber.Router, trashHandler *handlers.TrashHandler) {
+	// GET /expenses/trash - Get deleted expenses
+	router.Get("/expenses/trash", trashHandler.GetExpenses)
+
+	// DELETE /expenses/trash - Permanently delete all deleted expenses
+	router.Delete("/expenses/trash", trashHandler.EmptyExpenses)
+
+	// DELETE /expenses/trash/:id - Permanently delete a deleted expense
+	router.Delete("/expenses/trash/:id", trashHandler.PurgeExpense)
+
+	// POST /expenses/:id/restore - Restore a deleted expense
+	router.Post("/expenses/:id/restore", trashHandler.RestoreExpense)
+
+	// GET /categories/trash - Get deleted categories
+	router.Get("/categories/trash", trashHandler.GetCategories)
+
+	// DELETE /categories/trash/:id - Permanently delete a deleted category
+	router.Delete("/categories/trash/:id", trashHandler.PurgeCategory)
+
+	// POST /categories/:id/restore - Restore a deleted category
+	router.Post("/categories/:id/restore", trashHandler.RestoreCategory)
+}
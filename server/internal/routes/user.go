@@ -10,6 +10,9 @@ func SetupUserRoutes(router fiber.Router, userHandler *handlers.UserHandler) {
 	// GET /users/me - Get current user (requires auth)
 	router.Get("/users/me", userHandler.GetMe)
 
+	// PATCH /users/me - Update current user settings (base currency)
+	router.Patch("/users/me", userHandler.UpdateMe)
+
 	// Admin routes (require admin access)
 	admin := router.Group("", middleware.AdminMiddleware())
 
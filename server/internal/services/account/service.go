@@ -0,0 +1,448 @@
+package account
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"gorm.io/gorm"
+)
+
+// Service handles account and transfer business logic
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new account service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// CreateAccountInput represents the input for creating an account
+type CreateAccountInput struct {
+	Name           string       `json:"name" validate:"required"`
+	Type           string       `json:"type" validate:"required"`
+	Currency       string       `json:"currency"` // defaults to the user's base currency
+	OpeningBalance money.Amount `json:"opening_balance"`
+}
+
+// UpdateAccountInput represents the input for partially updating an
+// account. The currency can't be changed once the account exists.
+type UpdateAccountInput struct {
+	Name           *string       `json:"name"`
+	Type           *string       `json:"type"`
+	OpeningBalance *money.Amount `json:"opening_balance"`
+}
+
+// TransferInput represents the input for recording a transfer
+type TransferInput struct {
+	FromAccountID uint          `json:"from_account_id" validate:"required"`
+	ToAccountID   uint          `json:"to_account_id" validate:"required"`
+	Amount        money.Amount  `json:"amount" validate:"required,gt=0"`
+	ToAmount      *money.Amount `json:"to_amount"` // required between accounts in different currencies
+	Date          time.Time     `json:"date"`
+	Note          string        `json:"note"`
+}
+
+// AccountBalance is an account with its current balance
+type AccountBalance struct {
+	models.Account
+	Balance money.Amount `json:"balance"`
+}
+
+// BalancePoint is an account's balance at the end of a day
+type BalancePoint struct {
+	Date    string       `json:"date"`
+	Change  money.Amount `json:"change"`
+	Balance money.Amount `json:"balance"`
+}
+
+// BalanceHistory is the day by day balance of an account over a date range.
+// StartBalance is the balance before the first day of the range; days
+// without expenses, incomes or transfers are left out.
+type BalanceHistory struct {
+	Account      models.Account `json:"account"`
+	StartBalance money.Amount   `json:"start_balance"`
+	EndBalance   money.Amount   `json:"end_balance"`
+	Points       []BalancePoint `json:"points"`
+}
+
+// ValidType reports whether the account type is supported
+func ValidType(accountType string) bool {
+	switch accountType {
+	case models.AccountCash, models.AccountBank, models.AccountCard, models.AccountWallet:
+		return true
+	}
+	return false
+}
+
+// Check verifies that an account belongs to the user and uses the given
+// currency, so expenses and incomes can be booked against its balance.
+// Pass a transaction to make it part of a larger write.
+func Check(db *gorm.DB, userID, accountID uint, currencyCode string) error {
+	var account models.Account
+	if err := db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("account not found")
+		}
+		return err
+	}
+	if account.Currency != currencyCode {
+		return errors.New("currency must match the account currency")
+	}
+	return nil
+}
+
+// GetByUser retrieves all accounts of a user with their current balances
+func (s *Service) GetByUser(userID uint) ([]AccountBalance, error) {
+	var accounts []models.Account
+	if err := s.db.Where("user_id = ?", userID).Order("name ASC, id ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return []AccountBalance{}, nil
+	}
+
+	ids := make([]uint, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	changes, err := s.balanceChanges(ids, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]AccountBalance, len(accounts))
+	for i, account := range accounts {
+		balances[i] = AccountBalance{
+			Account: account,
+			Balance: account.OpeningBalance + changes[account.ID],
+		}
+	}
+	return balances, nil
+}
+
+// GetByID retrieves one of the user's accounts with its current balance
+func (s *Service) GetByID(id, userID uint) (*AccountBalance, error) {
+	account, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.balanceChanges([]uint{account.ID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountBalance{
+		Account: *account,
+		Balance: account.OpeningBalance + changes[account.ID],
+	}, nil
+}
+
+// balanceChanges sums the expenses and transfers of each account, only
+// counting those dated before the given day when before is set
+func (s *Service) balanceChanges(accountIDs []uint, before *time.Time) (map[uint]money.Amount, error) {
+	var rows []struct {
+		AccountID uint
+		Change    money.Amount
+	}
+
+	// A NULL cut-off day includes everything
+	var cutoff *string
+	if before != nil {
+		day := before.Format("2006-01-02")
+		cutoff = &day
+	}
+
+	err := s.db.Raw(`
+		SELECT t.account_id, SUM(t.change) AS change
+		FROM (
+			SELECT account_id, -total AS change FROM expenses
+			WHERE account_id IN @ids AND deleted_at IS NULL AND (CAST(@before AS date) IS NULL OR DATE(expense_date) < CAST(@before AS date))
+			UNION ALL
+			SELECT account_id, amount FROM incomes
+			WHERE account_id IN @ids AND (CAST(@before AS date) IS NULL OR DATE(date) < CAST(@before AS date))
+			UNION ALL
+			SELECT from_account_id, -amount FROM transfers
+			WHERE from_account_id IN @ids AND (CAST(@before AS date) IS NULL OR DATE(date) < CAST(@before AS date))
+			UNION ALL
+			SELECT to_account_id, to_amount FROM transfers
+			WHERE to_account_id IN @ids AND (CAST(@before AS date) IS NULL OR DATE(date) < CAST(@before AS date))
+		) t
+		GROUP BY t.account_id`,
+		map[string]interface{}{"ids": accountIDs, "before": cutoff},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[uint]money.Amount, len(rows))
+	for _, row := range rows {
+		changes[row.AccountID] = row.Change
+	}
+	return changes, nil
+}
+
+// GetHistory returns the day by day balance of one of the user's accounts
+// between startDate and endDate (inclusive)
+func (s *Service) GetHistory(id, userID uint, startDate, endDate time.Time) (*BalanceHistory, error) {
+	account, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything before the range is folded into the starting balance
+	changes, err := s.balanceChanges([]uint{account.ID}, &startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &BalanceHistory{
+		Account:      *account,
+		StartBalance: account.OpeningBalance + changes[account.ID],
+		Points:       []BalancePoint{},
+	}
+
+	var days []struct {
+		Date   string
+		Change money.Amount
+	}
+	err = s.db.Raw(`
+		SELECT t.date, SUM(t.change) AS change
+		FROM (
+			SELECT TO_CHAR(expense_date, 'YYYY-MM-DD') AS date, -total AS change FROM expenses
+			WHERE account_id = @id AND deleted_at IS NULL
+			UNION ALL
+			SELECT TO_CHAR(date, 'YYYY-MM-DD'), amount FROM incomes WHERE account_id = @id
+			UNION ALL
+			SELECT TO_CHAR(date, 'YYYY-MM-DD'), -amount FROM transfers WHERE from_account_id = @id
+			UNION ALL
+			SELECT TO_CHAR(date, 'YYYY-MM-DD'), to_amount FROM transfers WHERE to_account_id = @id
+		) t
+		WHERE t.date BETWEEN @start AND @end
+		GROUP BY t.date
+		ORDER BY t.date`,
+		map[string]interface{}{
+			"id":    account.ID,
+			"start": startDate.Format("2006-01-02"),
+			"end":   endDate.Format("2006-01-02"),
+		},
+	).Scan(&days).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balance := history.StartBalance
+	for _, day := range days {
+		balance += day.Change
+		history.Points = append(history.Points, BalancePoint{
+			Date:    day.Date,
+			Change:  day.Change,
+			Balance: balance,
+		})
+	}
+	history.EndBalance = balance
+
+	return history, nil
+}
+
+// Create creates a new account
+func (s *Service) Create(userID uint, input CreateAccountInput) (*models.Account, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	accountType := strings.ToLower(strings.TrimSpace(input.Type))
+	if !ValidType(accountType) {
+		return nil, errors.New("invalid account type")
+	}
+
+	currencyCode := input.Currency
+	if currencyCode == "" {
+		var user models.User
+		if err := s.db.Select("base_currency").First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		currencyCode = user.BaseCurrency
+	}
+	currencyCode, ok := currency.NormalizeCode(currencyCode)
+	if !ok {
+		return nil, errors.New("invalid currency")
+	}
+
+	account := &models.Account{
+		UserID:         userID,
+		Name:           name,
+		Type:           accountType,
+		Currency:       currencyCode,
+		OpeningBalance: input.OpeningBalance,
+	}
+	if err := s.db.Create(account).Error; err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Update applies a partial update to one of the user's accounts
+func (s *Service) Update(id, userID uint, input UpdateAccountInput) (*models.Account, error) {
+	account, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		account.Name = name
+	}
+	if input.Type != nil {
+		accountType := strings.ToLower(strings.TrimSpace(*input.Type))
+		if !ValidType(accountType) {
+			return nil, errors.New("invalid account type")
+		}
+		account.Type = accountType
+	}
+	if input.OpeningBalance != nil {
+		account.OpeningBalance = *input.OpeningBalance
+	}
+
+	if err := s.db.Save(account).Error; err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Delete deletes one of the user's accounts. Accounts that expenses (even
+// in the trash), incomes or transfers refer to can't be deleted.
+func (s *Service) Delete(id, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var account models.Account
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&account).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("account not found")
+			}
+			return err
+		}
+
+		var count int64
+		tx.Unscoped().Model(&models.Expense{}).Where("account_id = ?", account.ID).Count(&count)
+		if count > 0 {
+			return errors.New("account is still in use")
+		}
+		tx.Model(&models.Transfer{}).Where("from_account_id = ? OR to_account_id = ?", account.ID, account.ID).Count(&count)
+		if count > 0 {
+			return errors.New("account is still in use")
+		}
+		tx.Model(&models.Income{}).Where("account_id = ?", account.ID).Count(&count)
+		if count > 0 {
+			return errors.New("account is still in use")
+		}
+
+		return tx.Delete(&account).Error
+	})
+}
+
+// GetTransfers lists the user's transfers, optionally only those of one account
+func (s *Service) GetTransfers(userID, accountID uint) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+
+	query := s.db.
+		Preload("FromAccount").
+		Preload("ToAccount").
+		Where("user_id = ?", userID)
+	if accountID != 0 {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+	}
+
+	if err := query.Order("date DESC, id DESC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// CreateTransfer records a transfer between two of the user's accounts
+func (s *Service) CreateTransfer(userID uint, input TransferInput) (*models.Transfer, error) {
+	if input.FromAccountID == input.ToAccountID {
+		return nil, errors.New("cannot transfer to the same account")
+	}
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	from, err := s.find(input.FromAccountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.find(input.ToAccountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	toAmount := input.Amount
+	if input.ToAmount != nil {
+		toAmount = *input.ToAmount
+	} else if from.Currency != to.Currency {
+		return nil, errors.New("to_amount is required between accounts in different currencies")
+	}
+	if toAmount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	if input.Date.IsZero() {
+		input.Date = time.Now()
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        input.Amount,
+		ToAmount:      toAmount,
+		Date:          input.Date,
+		Note:          input.Note,
+	}
+	if err := s.db.Create(transfer).Error; err != nil {
+		return nil, err
+	}
+
+	// Load account relationships
+	s.db.Preload("FromAccount").Preload("ToAccount").First(transfer, transfer.ID)
+
+	return transfer, nil
+}
+
+// DeleteTransfer deletes one of the user's transfers
+func (s *Service) DeleteTransfer(id, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Transfer{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("transfer not found")
+	}
+	return nil
+}
+
+// find loads one of the user's accounts
+func (s *Service) find(id, userID uint) (*models.Account, error) {
+	var account models.Account
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("account not found")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,477 @@
+package budget
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Budget statuses
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusExceeded = "exceeded"
+)
+
+// WarningPercent is the share of the limit at which a budget turns to warning
+const WarningPercent = 80
+
+// AlertThresholds are the percentages of the limit that raise an alert when
+// spending crosses them
+var AlertThresholds = []int{WarningPercent, 100}
+
+// Service handles budget business logic
+type Service struct {
+	db             *gorm.DB
+	expenseService *expense.Service
+}
+
+// NewService creates a new budget service instance. Spending is totalled
+// with the expense service so budgets agree with expense analytics.
+func NewService(db *gorm.DB, expenseService *expense.Service) *Service {
+	return &Service{
+		db:             db,
+		expenseService: expenseService,
+	}
+}
+
+// CreateBudgetInput represents the input for creating a budget
+type CreateBudgetInput struct {
+	CategoryID *uint        `json:"category_id"` // omit for a budget on all spending
+	Amount     money.Amount `json:"amount" validate:"required,gt=0"`
+	Period     string       `json:"period" validate:"required"`
+	StartDate  time.Time    `json:"start_date"` // defaults to today
+	EndDate    *time.Time   `json:"end_date"`
+	Rollover   bool         `json:"rollover"`
+}
+
+// UpdateBudgetInput represents the input for partially updating a budget.
+// Nil fields are left unchanged.
+type UpdateBudgetInput struct {
+	CategoryID *uint         `json:"category_id"` // 0 makes it a budget on all spending
+	Amount     *money.Amount `json:"amount"`
+	Period     *string       `json:"period"`
+	StartDate  *time.Time    `json:"start_date"`
+	EndDate    *time.Time    `json:"end_date"`
+	ClearEnd   bool          `json:"clear_end_date"`
+	Rollover   *bool         `json:"rollover"`
+}
+
+// BudgetStatus is how a budget stands in the period containing a date.
+// Amounts are in Currency, the user's base currency.
+type BudgetStatus struct {
+	Budget      models.Budget `json:"budget"`
+	Currency    string        `json:"currency"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	CarriedOver money.Amount  `json:"carried_over"` // left over from earlier periods, negative when overspent
+	Limit       money.Amount  `json:"limit"`        // amount plus what was carried over
+	Spent       money.Amount  `json:"spent"`
+	Remaining   money.Amount  `json:"remaining"` // negative when overspent
+	Percent     float64       `json:"percent"`   // spent as a percentage of the limit
+	Status      string        `json:"status"`    // ok, warning or exceeded
+}
+
+// ValidPeriod reports whether the budget period is supported
+func ValidPeriod(period string) bool {
+	switch period {
+	case models.BudgetWeekly, models.BudgetMonthly, models.BudgetCustom:
+		return true
+	}
+	return false
+}
+
+// GetByUser retrieves all budgets of a user
+func (s *Service) GetByUser(userID uint) ([]models.Budget, error) {
+	var budgets []models.Budget
+
+	err := s.db.Preload("Category").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// GetByID retrieves one of the user's budgets
+func (s *Service) GetByID(id, userID uint) (*models.Budget, error) {
+	var budget models.Budget
+	err := s.db.Preload("Category").Where("id = ? AND user_id = ?", id, userID).First(&budget).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// Create creates a new budget
+func (s *Service) Create(userID uint, input CreateBudgetInput) (*models.Budget, error) {
+	if input.CategoryID != nil && *input.CategoryID == 0 {
+		input.CategoryID = nil
+	}
+
+	// Start today if no start date is provided
+	if input.StartDate.IsZero() {
+		input.StartDate = time.Now()
+	}
+
+	budget := &models.Budget{
+		UserID:     userID,
+		CategoryID: input.CategoryID,
+		Amount:     input.Amount,
+		Period:     input.Period,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+		Rollover:   input.Rollover,
+	}
+	if err := s.validate(budget); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(budget).Error; err != nil {
+		return nil, err
+	}
+
+	// Load category relationship
+	s.db.Preload("Category").First(budget, budget.ID)
+
+	return budget, nil
+}
+
+// Update applies a partial update to one of the user's budgets
+func (s *Service) Update(id, userID uint, input UpdateBudgetInput) (*models.Budget, error) {
+	budget, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.CategoryID != nil {
+		if *input.CategoryID == 0 {
+			budget.CategoryID = nil
+		} else {
+			budget.CategoryID = input.CategoryID
+		}
+	}
+	if input.Amount != nil {
+		budget.Amount = *input.Amount
+	}
+	if input.Period != nil {
+		budget.Period = *input.Period
+	}
+	if input.StartDate != nil {
+		budget.StartDate = *input.StartDate
+	}
+	if input.ClearEnd {
+		budget.EndDate = nil
+	} else if input.EndDate != nil {
+		budget.EndDate = input.EndDate
+	}
+	if input.Rollover != nil {
+		budget.Rollover = *input.Rollover
+	}
+	if err := s.validate(budget); err != nil {
+		return nil, err
+	}
+
+	budget.Category = nil
+	if err := s.db.Save(budget).Error; err != nil {
+		return nil, err
+	}
+
+	// Load category relationship
+	s.db.Preload("Category").First(budget, budget.ID)
+
+	return budget, nil
+}
+
+// Delete deletes one of the user's budgets along with its alerts
+func (s *Service) Delete(id, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Budget{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("budget not found")
+		}
+		return tx.Where("budget_id = ?", id).Delete(&models.BudgetAlert{}).Error
+	})
+}
+
+// GetStatus returns the status of a budget in the period containing date
+func (s *Service) GetStatus(id, userID uint, date time.Time) (*BudgetStatus, error) {
+	budget, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(budget, date)
+}
+
+// GetStatuses returns the current status of every budget of the user that
+// is active at now
+func (s *Service) GetStatuses(userID uint, now time.Time) ([]BudgetStatus, error) {
+	budgets, err := s.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []BudgetStatus{}
+	for i := range budgets {
+		if _, _, _, ok := periodAt(&budgets[i], now); !ok {
+			continue
+		}
+		status, err := s.status(&budgets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	return statuses, nil
+}
+
+// CheckAlerts records an alert for every threshold the user's current
+// spending has crossed in any active budget. Each threshold fires once per
+// budget period. It is registered as a callback for new expenses, so
+// failures are logged rather than returned.
+func (s *Service) CheckAlerts(userID uint) {
+	statuses, err := s.GetStatuses(userID, time.Now())
+	if err != nil {
+		log.Printf("budgets: failed to check alerts for user %d: %v", userID, err)
+		return
+	}
+
+	for _, status := range statuses {
+		for _, threshold := range AlertThresholds {
+			if status.Percent < float64(threshold) {
+				continue
+			}
+			alert := &models.BudgetAlert{
+				UserID:      userID,
+				BudgetID:    status.Budget.ID,
+				PeriodStart: status.PeriodStart,
+				Threshold:   threshold,
+				Spent:       status.Spent,
+				Limit:       status.Limit,
+			}
+			result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+			if result.Error != nil {
+				log.Printf("budgets: failed to record alert for budget %d: %v", status.Budget.ID, result.Error)
+				continue
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("budgets: budget %d of user %d reached %d%% (%s of %s %s)",
+					status.Budget.ID, userID, threshold, status.Spent, status.Limit, status.Currency)
+			}
+		}
+	}
+}
+
+// GetAlerts retrieves the user's budget alerts, newest first
+func (s *Service) GetAlerts(userID uint, unreadOnly bool) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+
+	query := s.db.Preload("Budget").Preload("Budget.Category").Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Order("created_at DESC, id DESC").Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// MarkAlertRead marks one of the user's alerts as read
+func (s *Service) MarkAlertRead(id, userID uint) (*models.BudgetAlert, error) {
+	var alert models.BudgetAlert
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+
+	if alert.ReadAt == nil {
+		now := time.Now()
+		alert.ReadAt = &now
+		if err := s.db.Model(&alert).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &alert, nil
+}
+
+// status computes the status of a budget in the period containing date
+func (s *Service) status(budget *models.Budget, date time.Time) (*BudgetStatus, error) {
+	start, end, index, ok := periodAt(budget, date)
+	if !ok {
+		return nil, errors.New("budget is not active on this date")
+	}
+
+	currencyCode, spent, err := s.spent(budget, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BudgetStatus{
+		Budget:      *budget,
+		Currency:    currencyCode,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Limit:       budget.Amount,
+		Spent:       spent,
+	}
+
+	// Everything budgeted for the earlier periods minus what was spent in them
+	if budget.Rollover && index > 0 {
+		first, _, _, _ := periodAt(budget, budget.StartDate)
+		_, spentBefore, err := s.spent(budget, first, start.Add(-time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+		status.CarriedOver = budget.Amount*money.Amount(index) - spentBefore
+		status.Limit += status.CarriedOver
+	}
+
+	status.Remaining = status.Limit - status.Spent
+	if status.Limit > 0 {
+		status.Percent = math.Round(float64(status.Spent)/float64(status.Limit)*10000) / 100
+	} else {
+		// Nothing left to spend in this period
+		status.Percent = 100
+	}
+
+	switch {
+	case status.Percent >= 100:
+		status.Status = StatusExceeded
+	case status.Percent >= WarningPercent:
+		status.Status = StatusWarning
+	default:
+		status.Status = StatusOK
+	}
+
+	return status, nil
+}
+
+// spent totals the budget's spending between start and end (inclusive)
+// using the same aggregation as expense analytics
+func (s *Service) spent(budget *models.Budget, start, end time.Time) (string, money.Amount, error) {
+	spending, err := s.expenseService.GetSpending(expense.AnalyticsQuery{
+		UserID:    budget.UserID,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	if budget.CategoryID == nil {
+		return spending.Currency, spending.Total, nil
+	}
+	for _, category := range spending.ByCategory {
+		if category.CategoryID == *budget.CategoryID {
+			return spending.Currency, category.Total, nil
+		}
+	}
+	return spending.Currency, 0, nil
+}
+
+// validate checks a new or updated budget
+func (s *Service) validate(budget *models.Budget) error {
+	if budget.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if !ValidPeriod(budget.Period) {
+		return errors.New("invalid period")
+	}
+	if budget.Period == models.BudgetCustom && budget.EndDate == nil {
+		return errors.New("end_date is required for custom budgets")
+	}
+	if budget.EndDate != nil && budget.EndDate.Before(budget.StartDate) {
+		return errors.New("end_date must be after start_date")
+	}
+
+	if budget.CategoryID != nil {
+		var count int64
+		err := s.db.Model(&models.Category{}).
+			Where("id = ? AND (user_id = ? OR user_id IS NULL)", *budget.CategoryID, budget.UserID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("category not found")
+		}
+	}
+
+	return nil
+}
+
+// periodAt returns the first and last instant of the budget period
+// containing date and how many periods came before it. ok is false when
+// the budget does not cover date.
+func periodAt(budget *models.Budget, date time.Time) (start, end time.Time, index int, ok bool) {
+	loc := budget.StartDate.Location()
+	date = date.In(loc)
+	first := startOfDay(budget.StartDate)
+	if date.Before(first) {
+		return start, end, 0, false
+	}
+
+	var last time.Time
+	if budget.EndDate != nil {
+		last = startOfDay(budget.EndDate.In(loc)).AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if date.After(last) {
+			return start, end, 0, false
+		}
+	}
+
+	switch budget.Period {
+	case models.BudgetWeekly:
+		index = int(math.Round(startOfDay(date).Sub(first).Hours()/24)) / 7
+		start = first.AddDate(0, 0, 7*index)
+		end = start.AddDate(0, 0, 7).Add(-time.Nanosecond)
+	case models.BudgetMonthly:
+		first = time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, loc)
+		index = (date.Year()-first.Year())*12 + int(date.Month()-first.Month())
+		start = first.AddDate(0, index, 0)
+		end = start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	case models.BudgetCustom:
+		if budget.EndDate == nil {
+			return start, end, 0, false
+		}
+		start, end = first, last
+	default:
+		return start, end, 0, false
+	}
+
+	// The first period starts and the last one ends with the budget
+	if start.Before(startOfDay(budget.StartDate)) {
+		start = startOfDay(budget.StartDate)
+	}
+	if budget.EndDate != nil && end.After(last) {
+		end = last
+	}
+
+	return start, end, index, true
+}
+
+// startOfDay truncates t to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
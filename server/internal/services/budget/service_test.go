@@ -0,0 +1,121 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+)
+
+func TestPeriodAt(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	endOf := func(t time.Time) time.Time {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	marchEnd := day(2026, 3, 10)
+
+	tests := []struct {
+		name      string
+		budget    models.Budget
+		date      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantIndex int
+		wantOK    bool
+	}{
+		{
+			name:      "weekly, first day",
+			budget:    models.Budget{Period: models.BudgetWeekly, StartDate: day(2026, 3, 4).Add(10 * time.Hour)},
+			date:      day(2026, 3, 4).Add(9 * time.Hour),
+			wantStart: day(2026, 3, 4), wantEnd: endOf(day(2026, 3, 10)), wantIndex: 0, wantOK: true,
+		},
+		{
+			name:      "weekly, second period",
+			budget:    models.Budget{Period: models.BudgetWeekly, StartDate: day(2026, 3, 4)},
+			date:      day(2026, 3, 11),
+			wantStart: day(2026, 3, 11), wantEnd: endOf(day(2026, 3, 17)), wantIndex: 1, wantOK: true,
+		},
+		{
+			name:   "weekly, before start",
+			budget: models.Budget{Period: models.BudgetWeekly, StartDate: day(2026, 3, 4)},
+			date:   endOf(day(2026, 3, 3)),
+		},
+		{
+			name:      "monthly, first period starts with the budget",
+			budget:    models.Budget{Period: models.BudgetMonthly, StartDate: day(2026, 1, 15)},
+			date:      day(2026, 1, 20),
+			wantStart: day(2026, 1, 15), wantEnd: endOf(day(2026, 1, 31)), wantIndex: 0, wantOK: true,
+		},
+		{
+			name:      "monthly, calendar month",
+			budget:    models.Budget{Period: models.BudgetMonthly, StartDate: day(2026, 1, 15)},
+			date:      day(2026, 2, 28).Add(23 * time.Hour),
+			wantStart: day(2026, 2, 1), wantEnd: endOf(day(2026, 2, 28)), wantIndex: 1, wantOK: true,
+		},
+		{
+			name:      "monthly, across a year",
+			budget:    models.Budget{Period: models.BudgetMonthly, StartDate: day(2025, 11, 3)},
+			date:      day(2026, 2, 1),
+			wantStart: day(2026, 2, 1), wantEnd: endOf(day(2026, 2, 28)), wantIndex: 3, wantOK: true,
+		},
+		{
+			name:      "monthly, last period ends with the budget",
+			budget:    models.Budget{Period: models.BudgetMonthly, StartDate: day(2026, 1, 15), EndDate: &marchEnd},
+			date:      day(2026, 3, 5),
+			wantStart: day(2026, 3, 1), wantEnd: endOf(marchEnd), wantIndex: 2, wantOK: true,
+		},
+		{
+			name:   "monthly, after the end",
+			budget: models.Budget{Period: models.BudgetMonthly, StartDate: day(2026, 1, 15), EndDate: &marchEnd},
+			date:   day(2026, 3, 11),
+		},
+		{
+			name:      "custom",
+			budget:    models.Budget{Period: models.BudgetCustom, StartDate: day(2026, 2, 20), EndDate: &marchEnd},
+			date:      day(2026, 3, 1),
+			wantStart: day(2026, 2, 20), wantEnd: endOf(marchEnd), wantIndex: 0, wantOK: true,
+		},
+		{
+			name:   "custom without end date",
+			budget: models.Budget{Period: models.BudgetCustom, StartDate: day(2026, 2, 20)},
+			date:   day(2026, 3, 1),
+		},
+		{
+			name:   "unknown period",
+			budget: models.Budget{Period: "daily", StartDate: day(2026, 2, 20)},
+			date:   day(2026, 3, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, index, ok := periodAt(&tt.budget, tt.date)
+			if ok != tt.wantOK {
+				t.Fatalf("periodAt() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) || index != tt.wantIndex {
+				t.Errorf("periodAt() = %v - %v (#%d), want %v - %v (#%d)", start, end, index, tt.wantStart, tt.wantEnd, tt.wantIndex)
+			}
+		})
+	}
+}
+
+// Weekly periods stay aligned to midnight across a daylight saving change
+func TestPeriodAtDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	// Clocks go forward on 2026-03-29, so that week is an hour short
+	budget := models.Budget{Period: models.BudgetWeekly, StartDate: time.Date(2026, 3, 23, 0, 0, 0, 0, loc)}
+	start, _, index, ok := periodAt(&budget, time.Date(2026, 3, 30, 12, 0, 0, 0, loc))
+	if !ok || index != 1 || !start.Equal(time.Date(2026, 3, 30, 0, 0, 0, 0, loc)) {
+		t.Errorf("periodAt() = %v (#%d), %v; want 2026-03-30 00:00 (#1)", start, index, ok)
+	}
+}
@@ -2,6 +2,8 @@ package category
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/parvejmia9/minflow/server/internal/models"
 	"gorm.io/gorm"
@@ -32,11 +34,11 @@ func (s *Service) GetAll(userID uint) ([]models.Category, error) {
 	return categories, nil
 }
 
-// GetByID retrieves a single category by ID
-func (s *Service) GetByID(id uint) (*models.Category, error) {
+// GetByID retrieves a default category or one of the user's by ID
+func (s *Service) GetByID(id, userID uint) (*models.Category, error) {
 	var category models.Category
 
-	result := s.db.First(&category, id)
+	result := s.db.Where("id = ? AND (user_id IS NULL OR user_id = ?)", id, userID).First(&category)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("category not found")
@@ -88,25 +90,63 @@ func (s *Service) SeedDefaultCategories() error {
 	return nil
 }
 
-// Update updates an existing category
-func (s *Service) Update(id uint, category *models.Category) error {
-	result := s.db.Model(&models.Category{}).Where("id = ?", id).Updates(category)
+// Update renames a category created by the user. Default categories can't
+// be changed. When expected is set, the category is only renamed if it was
+// last updated at expected.
+func (s *Service) Update(id, userID uint, name string, expected *time.Time) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	var category models.Category
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+
+	query := s.db.Model(&category)
+	if expected != nil {
+		query = query.Where("updated_at = ?", *expected)
+	}
+	result := query.Update("name", name)
 	if result.Error != nil {
-		return result.Error
+		return nil, result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("category not found")
+		return nil, errors.New("category was modified")
 	}
-	return nil
+
+	return s.GetByID(category.ID, userID)
 }
 
-// Delete soft deletes a category
-func (s *Service) Delete(id uint) error {
-	result := s.db.Delete(&models.Category{}, id)
+// Delete soft deletes a category created by the user. Default categories
+// can't be deleted. When expected is set, the category is only deleted if
+// it was last updated at expected.
+func (s *Service) Delete(id, userID uint, expected *time.Time) error {
+	query := s.db.Where("id = ? AND user_id = ?", id, userID)
+	if expected != nil {
+		query = query.Where("updated_at = ?", *expected)
+	}
+	result := query.Delete(&models.Category{})
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
+		// Tell a category that changed apart from one that isn't there
+		var count int64
+		if expected != nil {
+			err := s.db.Model(&models.Category{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error
+			if err != nil {
+				return err
+			}
+		}
+		if count > 0 {
+			return errors.New("category was modified")
+		}
 		return errors.New("category not found")
 	}
 	return nil
@@ -0,0 +1,196 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service handles currencies and exchange rates
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new currency service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// RateInput represents the input for creating or replacing an exchange rate
+type RateInput struct {
+	FromCurrency string  `json:"from_currency" validate:"required"`
+	ToCurrency   string  `json:"to_currency" validate:"required"`
+	Date         string  `json:"date" validate:"required"` // YYYY-MM-DD
+	Rate         float64 `json:"rate" validate:"required,gt=0"`
+}
+
+// NormalizeCode upper-cases a currency code and reports whether it looks
+// like an ISO 4217 code (three letters)
+func NormalizeCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return code, false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return code, false
+		}
+	}
+	return code, true
+}
+
+// ConvertedSQL returns an SQL expression converting the amount column into
+// the base currency (bound to the three ? placeholders) using the latest rate
+// on or before the date column, falling back to the inverse rate. It is NULL
+// when no rate is known.
+func ConvertedSQL(amount, currencyColumn, date string) string {
+	return fmt.Sprintf(`(%[1]s * CASE WHEN %[2]s = ? THEN 1 ELSE COALESCE(
+	(SELECT er.rate FROM exchange_rates er WHERE er.from_currency = %[2]s AND er.to_currency = ? AND er.date <= %[3]s ORDER BY er.date DESC LIMIT 1),
+	(SELECT 1 / er.rate FROM exchange_rates er WHERE er.from_currency = ? AND er.to_currency = %[2]s AND er.date <= %[3]s ORDER BY er.date DESC LIMIT 1)
+) END)`, amount, currencyColumn, date)
+}
+
+// GetRates lists exchange rates, optionally for a single currency pair
+func (s *Service) GetRates(from, to string) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+
+	query := s.db.Model(&models.ExchangeRate{})
+	if from != "" {
+		query = query.Where("from_currency = ?", from)
+	}
+	if to != "" {
+		query = query.Where("to_currency = ?", to)
+	}
+
+	if err := query.Order("date DESC, from_currency, to_currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// SetRate creates the rate for a currency pair and day, replacing any
+// existing rate for the same pair and day
+func (s *Service) SetRate(input RateInput) (*models.ExchangeRate, error) {
+	rate, err := parseRate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// DeleteRate deletes an exchange rate
+func (s *Service) DeleteRate(id uint) error {
+	result := s.db.Delete(&models.ExchangeRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("exchange rate not found")
+	}
+	return nil
+}
+
+// LoadFile loads exchange rates from a CSV file with the columns
+// date,from_currency,to_currency,rate (a header line is optional) and
+// returns the number of rates stored
+func (s *Service) LoadFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return s.Load(file)
+}
+
+// Load loads exchange rates in the LoadFile CSV format from a reader
+func (s *Service) Load(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	loaded := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return loaded, err
+		}
+		if len(record) != 4 {
+			return loaded, fmt.Errorf("line %d: expected date,from_currency,to_currency,rate", line)
+		}
+		// Skip the header line
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return loaded, fmt.Errorf("line %d: invalid rate %q", line, record[3])
+		}
+
+		_, err = s.SetRate(RateInput{
+			Date:         record[0],
+			FromCurrency: record[1],
+			ToCurrency:   record[2],
+			Rate:         value,
+		})
+		if err != nil {
+			return loaded, fmt.Errorf("line %d: %w", line, err)
+		}
+		loaded++
+	}
+
+	return loaded, nil
+}
+
+// parseRate validates a rate input
+func parseRate(input RateInput) (*models.ExchangeRate, error) {
+	from, ok := NormalizeCode(input.FromCurrency)
+	if !ok {
+		return nil, errors.New("invalid from_currency")
+	}
+	to, ok := NormalizeCode(input.ToCurrency)
+	if !ok {
+		return nil, errors.New("invalid to_currency")
+	}
+	if from == to {
+		return nil, errors.New("from_currency and to_currency must differ")
+	}
+	if input.Rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(input.Date))
+	if err != nil {
+		return nil, errors.New("invalid date (use YYYY-MM-DD)")
+	}
+
+	return &models.ExchangeRate{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Date:         date,
+		Rate:         input.Rate,
+	}, nil
+}
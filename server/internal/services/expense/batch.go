@@ -0,0 +1,216 @@
+package expense
+
+import (
+	"errors"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+	"github.com/parvejmia9/minflow/server/internal/services/tag"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Batch actions
+const (
+	BatchRecategorize = "recategorize"
+	BatchDelete       = "delete"
+	BatchShiftDate    = "shift_date"
+	BatchAddTag       = "add_tag"
+)
+
+// MaxBatchSize is the most expenses one batch request can change
+const MaxBatchSize = 1000
+
+// BatchInput describes a change applied to many expenses at once. The
+// expenses are selected by IDs, or by the filter passed to Batch when IDs
+// is empty.
+type BatchInput struct {
+	IDs        []uint `json:"ids"`
+	Action     string `json:"action"`
+	CategoryID uint   `json:"category_id"` // recategorize: the new category
+	Days       int    `json:"days"`        // shift_date: days to move by, negative moves back
+	Tag        string `json:"tag"`         // add_tag: tag name, created if missing
+}
+
+// BatchResult reports what a batch request did
+type BatchResult struct {
+	Action   string `json:"action"`
+	Matched  int    `json:"matched"`             // expenses selected
+	Affected int    `json:"affected"`            // expenses actually changed
+	NotFound []uint `json:"not_found,omitempty"` // requested IDs that aren't the user's expenses
+}
+
+// ValidBatchAction reports whether the batch action is supported
+func ValidBatchAction(action string) bool {
+	switch action {
+	case BatchRecategorize, BatchDelete, BatchShiftDate, BatchAddTag:
+		return true
+	}
+	return false
+}
+
+// Batch applies one action to the user's expenses matching input.IDs (or
+// the filter when no IDs are given) in a single transaction, recording a
+// revision for every expense it changes. Deleted expenses go to the trash.
+// The filter's UserID scopes the selection, so other users' expenses are
+// never touched.
+func (s *Service) Batch(filter ExpenseFilter, input BatchInput) (*BatchResult, error) {
+	if !ValidBatchAction(input.Action) {
+		return nil, errors.New("invalid batch action")
+	}
+	if len(input.IDs) == 0 && !filter.selective() {
+		return nil, errors.New("ids or a filter is required")
+	}
+	if len(input.IDs) > MaxBatchSize {
+		return nil, errors.New("too many expenses selected")
+	}
+
+	var tagName string
+	switch input.Action {
+	case BatchRecategorize:
+		if err := s.checkCategory(filter.UserID, input.CategoryID); err != nil {
+			return nil, err
+		}
+	case BatchShiftDate:
+		if input.Days == 0 {
+			return nil, errors.New("days must not be zero")
+		}
+	case BatchAddTag:
+		name, ok := tag.NormalizeName(input.Tag)
+		if !ok {
+			return nil, errors.New("invalid tag name")
+		}
+		tagName = name
+	}
+
+	userID := filter.UserID
+	result := &BatchResult{Action: input.Action}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var expenses []models.Expense
+		query := tx.Preload("Tags").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(filter.Scope)
+		if len(input.IDs) > 0 {
+			query = query.Where("expenses.id IN ?", input.IDs)
+		}
+		if err := query.Order("expenses.id ASC").Limit(MaxBatchSize + 1).Find(&expenses).Error; err != nil {
+			return err
+		}
+		if len(expenses) > MaxBatchSize {
+			return errors.New("too many expenses selected")
+		}
+		result.Matched = len(expenses)
+
+		if len(input.IDs) > 0 {
+			found := make(map[uint]bool, len(expenses))
+			for _, expense := range expenses {
+				found[expense.ID] = true
+			}
+			for _, id := range input.IDs {
+				if !found[id] {
+					result.NotFound = append(result.NotFound, id)
+					found[id] = true
+				}
+			}
+		}
+
+		var batchTag *models.Tag
+		if input.Action == BatchAddTag {
+			tags, err := tag.FindOrCreate(tx, userID, []string{tagName})
+			if err != nil {
+				return err
+			}
+			batchTag = &tags[0]
+		}
+
+		now := time.Now()
+		for i := range expenses {
+			before := expenses[i]
+			after := expenses[i]
+
+			switch input.Action {
+			case BatchDelete:
+				deleted := tx.Delete(&models.Expense{}, after.ID)
+				if deleted.Error != nil {
+					return deleted.Error
+				}
+				if deleted.RowsAffected == 0 {
+					continue
+				}
+				if err := revision.Record(tx, models.RevisionDelete, &userID, &before, nil); err != nil {
+					return err
+				}
+			case BatchRecategorize:
+				if after.CategoryID == input.CategoryID {
+					continue
+				}
+				after.CategoryID = input.CategoryID
+				if err := touch(tx, &after, now, map[string]interface{}{"category_id": after.CategoryID}); err != nil {
+					return err
+				}
+			case BatchShiftDate:
+				after.ExpenseDate = after.ExpenseDate.AddDate(0, 0, input.Days)
+				if err := touch(tx, &after, now, map[string]interface{}{"expense_date": after.ExpenseDate}); err != nil {
+					return err
+				}
+			case BatchAddTag:
+				if hasTag(after.Tags, batchTag.ID) {
+					continue
+				}
+				err := tx.Exec("INSERT INTO expense_tags (expense_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", after.ID, batchTag.ID).Error
+				if err != nil {
+					return err
+				}
+				after.Tags = append(append([]models.Tag(nil), before.Tags...), *batchTag)
+				if err := touch(tx, &after, now, map[string]interface{}{}); err != nil {
+					return err
+				}
+			}
+
+			if input.Action != BatchDelete {
+				if err := revision.Record(tx, models.RevisionUpdate, &userID, &before, &after); err != nil {
+					return err
+				}
+			}
+			result.Affected++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Affected > 0 && (input.Action == BatchRecategorize || input.Action == BatchShiftDate) {
+		s.notifyChange(userID)
+	}
+
+	return result, nil
+}
+
+// selective reports whether the filter narrows down the user's expenses at
+// all, so a batch never applies to everything by accident
+func (f ExpenseFilter) selective() bool {
+	return len(f.CategoryIDs) > 0 || len(f.Tags) > 0 || len(f.MerchantIDs) > 0 || len(f.AccountIDs) > 0 ||
+		f.StartDate != nil || f.EndDate != nil || f.MinTotal != nil || f.MaxTotal != nil || f.Search != ""
+}
+
+// touch writes columns of an expense without running the save hooks and
+// bumps its updated_at
+func touch(tx *gorm.DB, expense *models.Expense, now time.Time, columns map[string]interface{}) error {
+	columns["updated_at"] = now
+	expense.UpdatedAt = now
+	return tx.Model(expense).Omit(clause.Associations).UpdateColumns(columns).Error
+}
+
+// hasTag reports whether tags contains the tag with the given ID
+func hasTag(tags []models.Tag, id uint) bool {
+	for _, t := range tags {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
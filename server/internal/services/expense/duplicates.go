@@ -0,0 +1,260 @@
+package expense
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+)
+
+// DefaultDuplicateWindow is how far apart two expenses may be dated and
+// still count as likely duplicates
+const DefaultDuplicateWindow = 3 * 24 * time.Hour
+
+// nameSimilarity is the minimum edit-distance similarity (0-1) of two
+// normalized names for them to count as the same
+const nameSimilarity = 0.8
+
+// DuplicateWarning lists the existing expenses a new expense probably
+// duplicates. Index is the position of the new expense in the request.
+type DuplicateWarning struct {
+	Index            int              `json:"index"`
+	Duplicates       []models.Expense `json:"duplicates"`
+	DuplicateOfIndex *int             `json:"duplicate_of_index,omitempty"` // an earlier expense of the same request
+}
+
+// FindDuplicates checks new expenses against the user's existing expenses
+// and against each other. Two expenses are likely duplicates when they have
+// the same total and currency, dates within the duplicate window and similar
+// names.
+func (s *Service) FindDuplicates(userID uint, inputs []CreateExpenseInput) ([]DuplicateWarning, error) {
+	baseCurrency, err := s.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the values the expenses will be saved with
+	type candidate struct {
+		input    CreateExpenseInput
+		total    money.Amount
+		currency string
+		date     time.Time
+	}
+	now := time.Now()
+	candidates := make([]candidate, len(inputs))
+	totals := make([]money.Amount, 0, len(inputs))
+	var earliest, latest time.Time
+	for i, input := range inputs {
+		c := candidate{input: input, total: input.PerUnitCost.Mul(input.Unit), currency: baseCurrency, date: input.ExpenseDate}
+		if input.Currency != "" {
+			c.currency, _ = currency.NormalizeCode(input.Currency)
+		}
+		if c.date.IsZero() {
+			c.date = now
+		}
+		candidates[i] = c
+
+		totals = append(totals, c.total)
+		if earliest.IsZero() || c.date.Before(earliest) {
+			earliest = c.date
+		}
+		if latest.IsZero() || c.date.After(latest) {
+			latest = c.date
+		}
+	}
+	if len(totals) == 0 {
+		return nil, nil
+	}
+
+	var existing []models.Expense
+	err = s.db.
+		Preload("Category").
+		Where("user_id = ? AND total IN ?", userID, totals).
+		Where("expense_date BETWEEN ? AND ?", earliest.Add(-s.duplicateWindow), latest.Add(s.duplicateWindow)).
+		Order("expense_date ASC, id ASC").
+		Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []DuplicateWarning
+	for i, c := range candidates {
+		warning := DuplicateWarning{Index: i, Duplicates: []models.Expense{}}
+		for _, expense := range existing {
+			if expense.Total == c.total && expense.Currency == c.currency &&
+				s.withinWindow(expense.ExpenseDate, c.date) &&
+				!distinctExternalIDs(expense.ExternalID, c.input.ExternalID) &&
+				similarNames(expense.Name, c.input.Name) {
+				warning.Duplicates = append(warning.Duplicates, expense)
+			}
+		}
+
+		for j := 0; j < i; j++ {
+			earlier := candidates[j]
+			if earlier.total == c.total && earlier.currency == c.currency &&
+				s.withinWindow(earlier.date, c.date) &&
+				!distinctExternalIDs(earlier.input.ExternalID, c.input.ExternalID) &&
+				similarNames(earlier.input.Name, c.input.Name) {
+				index := j
+				warning.DuplicateOfIndex = &index
+				break
+			}
+		}
+
+		if len(warning.Duplicates) > 0 || warning.DuplicateOfIndex != nil {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings, nil
+}
+
+// GetDuplicateGroups finds groups of the user's existing expenses that look
+// like duplicates of each other. window overrides the configured duplicate
+// window when positive.
+func (s *Service) GetDuplicateGroups(userID uint, window time.Duration) ([][]models.Expense, error) {
+	if window <= 0 {
+		window = s.duplicateWindow
+	}
+
+	// Only expenses sharing a total and currency with another one can match
+	var expenses []models.Expense
+	err := s.db.
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Where("(total, currency) IN (?)", s.db.Model(&models.Expense{}).
+			Select("total, currency").
+			Where("user_id = ?", userID).
+			Group("total, currency").
+			Having("COUNT(*) > 1")).
+		Order("total ASC, currency ASC, expense_date ASC, id ASC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over matching pairs within each total/currency bucket
+	parent := make([]int, len(expenses))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range expenses {
+		for j := i + 1; j < len(expenses); j++ {
+			a, b := expenses[i], expenses[j]
+			if a.Total != b.Total || a.Currency != b.Currency {
+				break
+			}
+			if b.ExpenseDate.Sub(a.ExpenseDate) > window {
+				break
+			}
+			if !distinctExternalIDs(a.ExternalID, b.ExternalID) && similarNames(a.Name, b.Name) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]models.Expense)
+	var roots []int
+	for i, expense := range expenses {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], expense)
+	}
+
+	groups := [][]models.Expense{}
+	for _, root := range roots {
+		if group := members[root]; len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	// Most recent groups first
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a][len(groups[a])-1].ExpenseDate.After(groups[b][len(groups[b])-1].ExpenseDate)
+	})
+
+	return groups, nil
+}
+
+// withinWindow reports whether two dates are at most the duplicate window apart
+func (s *Service) withinWindow(a, b time.Time) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= s.duplicateWindow
+}
+
+// distinctExternalIDs reports whether both expenses came from an external
+// source with different IDs, e.g. two separate bank transactions
+func distinctExternalIDs(a, b *string) bool {
+	return a != nil && b != nil && *a != *b
+}
+
+// similarNames reports whether two expense names probably describe the same
+// purchase: equal after normalization, one containing the other, or only a
+// few edits apart
+func similarNames(a, b string) bool {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	if len(a) >= 4 && len(b) >= 4 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		return true
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1-float64(levenshtein(ra, rb))/float64(longest) >= nameSimilarity
+}
+
+// normalizeName lower-cases a name and reduces it to single-spaced words of
+// letters and digits
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,121 @@
+package expense
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"coffee", "coffee", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"café", "cafe", 1},
+		{"uber", "über", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein([]rune(tt.b), []rune(tt.a)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Coffee", "coffee"},
+		{"  Starbucks   #123 ", "starbucks 123"},
+		{"AMZN*Mktp-US", "amzn mktp us"},
+		{"Café Olé!", "café olé"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeName(tt.in); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarNames(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Coffee", "coffee", true},
+		{"Starbucks #123", "starbucks-123", true},
+		{"Starbucks", "Starbucks Coffee", true}, // one contains the other
+		{"Groceries", "Grocries", true},         // 1 edit in 9 letters
+		{"Lunch", "Lunch!", true},
+		{"Tea", "Tea time", false}, // too short to match by containment
+		{"Lunch", "Dinner", false},
+		{"Taxi", "Tax", false}, // 1 edit in 4 letters
+		{"Groceries", "Gas", false},
+		{"", "", false},
+		{"!!", "Coffee", false},
+	}
+
+	for _, tt := range tests {
+		if got := similarNames(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarNames(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := similarNames(tt.b, tt.a); got != tt.want {
+			t.Errorf("similarNames(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestWithinWindow(t *testing.T) {
+	s := &Service{duplicateWindow: DefaultDuplicateWindow}
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		other time.Time
+		want  bool
+	}{
+		{base, true},
+		{base.Add(DefaultDuplicateWindow), true},
+		{base.Add(-DefaultDuplicateWindow), true},
+		{base.Add(DefaultDuplicateWindow + time.Second), false},
+		{base.Add(-DefaultDuplicateWindow - time.Second), false},
+	}
+
+	for _, tt := range tests {
+		if got := s.withinWindow(base, tt.other); got != tt.want {
+			t.Errorf("withinWindow(%v, %v) = %v, want %v", base, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestDistinctExternalIDs(t *testing.T) {
+	a, b, a2 := "ofx:1:A", "ofx:1:B", "ofx:1:A"
+
+	tests := []struct {
+		name string
+		x, y *string
+		want bool
+	}{
+		{"both missing", nil, nil, false},
+		{"one missing", &a, nil, false},
+		{"same", &a, &a2, false},
+		{"different", &a, &b, true},
+	}
+
+	for _, tt := range tests {
+		if got := distinctExternalIDs(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: distinctExternalIDs() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package expense
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// headlineOptions configures the ts_headline snippets of search results
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5"
+
+// SearchResult is an expense matching a full-text search
+type SearchResult struct {
+	Expense models.Expense `json:"expense"`
+	Rank    float64        `json:"rank"`
+	Snippet string         `json:"snippet"` // matching text, hits wrapped in <mark></mark>
+}
+
+// SearchQuery turns free text into a tsquery that matches expenses
+// containing every word, each as a prefix: "coff bean" becomes
+// "coff:* & bean:*". It returns "" when the text has no words.
+func SearchQuery(text string) string {
+	// Apostrophes would split "uber's" into two words
+	text = strings.NewReplacer("'", "", "’", "").Replace(text)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = strings.ToLower(word) + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// Search finds the user's expenses matching text in their name, merchant,
+// category or notes, best matches first. The other filter conditions still
+// apply; filter.Search is replaced by the full-text match.
+func (s *Service) Search(filter ExpenseFilter, text string, limit, offset int) ([]SearchResult, int64, error) {
+	query := SearchQuery(text)
+	if query == "" {
+		return nil, 0, errors.New("search query is required")
+	}
+	filter.Search = ""
+
+	matches := func() *gorm.DB {
+		return s.db.Model(&models.Expense{}).
+			Joins("CROSS JOIN to_tsquery('english', ?) AS search_query", query).
+			Scopes(filter.Scope).
+			Where("expenses.search_vector @@ search_query")
+	}
+
+	var total int64
+	if err := matches().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []struct {
+		ID      uint
+		Rank    float64
+		Snippet string
+	}
+	err := matches().
+		Select("expenses.id, ts_rank(expenses.search_vector, search_query) AS rank, ts_headline('english', concat_ws(' | ', expenses.name, COALESCE(merchants.name, expenses.raw_merchant), categories.name, NULLIF(expenses.notes, '')), search_query, ?) AS snippet", headlineOptions).
+		Joins("LEFT JOIN merchants ON merchants.id = expenses.merchant_id").
+		Joins("LEFT JOIN categories ON categories.id = expenses.category_id").
+		Order("rank DESC, expenses.expense_date DESC, expenses.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	if len(hits) == 0 {
+		return results, total, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	var expenses []models.Expense
+	err = s.db.Preload("Category").Preload("Tags").Preload("Merchant").Preload("Account").
+		Where("id IN ?", ids).
+		Find(&expenses).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]models.Expense, len(expenses))
+	for _, expense := range expenses {
+		byID[expense.ID] = expense
+	}
+
+	// Keep the ranked order
+	for _, hit := range hits {
+		expense, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, SearchResult{Expense: expense, Rank: hit.Rank, Snippet: hit.Snippet})
+	}
+
+	return results, total, nil
+}
@@ -0,0 +1,33 @@
+package expense
+
+import "testing"
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"coffee", "coffee:*"},
+		{"coff bean", "coff:* & bean:*"},
+		{"  Coffee   BEANS ", "coffee:* & beans:*"},
+		{"uber's ride", "ubers:* & ride:*"},
+		{"uber’s", "ubers:*"},
+		{"café 2026", "café:* & 2026:*"},
+		// tsquery operators and quoting are dropped, never passed through
+		{"a & b | !c", "a:* & b:* & c:*"},
+		{"coffee:* <-> (tea)", "coffee:* & tea:*"},
+		{"'); DROP TABLE expenses; --", "drop:* & table:* & expenses:*"},
+		{`"quoted\"`, "quoted:*"},
+		{"", ""},
+		{"   ", ""},
+		{"&|!():*'", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := SearchQuery(tt.in); got != tt.want {
+				t.Errorf("SearchQuery(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,31 +2,181 @@ package expense
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/pagination"
+	"github.com/parvejmia9/minflow/server/internal/services/account"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"github.com/parvejmia9/minflow/server/internal/services/merchant"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+	"github.com/parvejmia9/minflow/server/internal/services/split"
+	"github.com/parvejmia9/minflow/server/internal/services/tag"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Service handles expense business logic
 type Service struct {
-	db *gorm.DB
+	db              *gorm.DB
+	duplicateWindow time.Duration
+	onChange        []func(userID uint)
 }
 
-// NewService creates a new expense service instance
-func NewService(db *gorm.DB) *Service {
+// NewService creates a new expense service instance. duplicateWindow is how
+// far apart similar expenses may be dated to be flagged as duplicates
+// (DefaultDuplicateWindow when zero).
+func NewService(db *gorm.DB, duplicateWindow time.Duration) *Service {
+	if duplicateWindow <= 0 {
+		duplicateWindow = DefaultDuplicateWindow
+	}
 	return &Service{
-		db: db,
+		db:              db,
+		duplicateWindow: duplicateWindow,
+	}
+}
+
+// OnChange registers fn to be called after expenses of a user are created,
+// or updated in a way that can change what they count towards (total,
+// category or date)
+func (s *Service) OnChange(fn func(userID uint)) {
+	s.onChange = append(s.onChange, fn)
+}
+
+// notifyChange runs the OnChange callbacks
+func (s *Service) notifyChange(userID uint) {
+	for _, fn := range s.onChange {
+		fn(userID)
 	}
 }
 
 // CreateExpenseInput represents the input for creating an expense
 type CreateExpenseInput struct {
-	Name        string    `json:"name" validate:"required"`
-	CategoryID  uint      `json:"category_id" validate:"required"`
-	Unit        float64   `json:"unit" validate:"required,gt=0"`
-	PerUnitCost float64   `json:"per_unit_cost" validate:"required,gt=0"`
-	ExpenseDate time.Time `json:"expense_date"`
+	Name        string       `json:"name" validate:"required"`
+	CategoryID  uint         `json:"category_id" validate:"required"`
+	Unit        float64      `json:"unit" validate:"required,gt=0"`
+	PerUnitCost money.Amount `json:"per_unit_cost" validate:"required,gt=0"`
+	ExpenseDate time.Time    `json:"expense_date"`
+	Currency    string       `json:"currency"` // defaults to the user's base currency
+	ExternalID  *string      `json:"external_id,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
+	AccountID   *uint        `json:"account_id,omitempty"` // account paid from, must use the expense currency
+	Tags        []string     `json:"tags,omitempty"`       // tag names, created if missing
+	Merchant    string       `json:"merchant,omitempty"`   // raw merchant, normalized via the user's alias rules
+}
+
+// RowError describes why a single row of a bulk request was rejected
+type RowError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// BulkValidationError is returned when one or more rows of a bulk request are invalid
+type BulkValidationError struct {
+	Rows []RowError
+}
+
+func (e *BulkValidationError) Error() string {
+	return "validation failed"
+}
+
+// UpdateExpenseInput represents the input for partially updating an expense.
+// Nil fields are left unchanged.
+type UpdateExpenseInput struct {
+	Name        *string       `json:"name"`
+	CategoryID  *uint         `json:"category_id"`
+	Unit        *float64      `json:"unit"`
+	PerUnitCost *money.Amount `json:"per_unit_cost"`
+	ExpenseDate *time.Time    `json:"expense_date"`
+	Currency    *string       `json:"currency"`
+	AccountID   *uint         `json:"account_id"` // 0 removes the account
+	Tags        *[]string     `json:"tags"`       // replaces all tags when set
+	Merchant    *string       `json:"merchant"`   // raw merchant, empty removes it
+	Notes       *string       `json:"notes"`
+}
+
+// ExpenseFilter narrows down a user's expenses. It is shared by listings,
+// analytics and exports so they all interpret filters the same way.
+type ExpenseFilter struct {
+	UserID      uint
+	CategoryIDs []uint
+	Tags        []string // normalized tag names, matches expenses with any of them
+	MerchantIDs []uint
+	AccountIDs  []uint
+	StartDate   *time.Time
+	EndDate     *time.Time
+	MinTotal    *money.Amount
+	MaxTotal    *money.Amount
+	Search      string
+	SortBy      string
+	SortDir     string
+}
+
+// sortColumns maps the accepted sort fields to their columns
+var sortColumns = map[string]string{
+	"expense_date": "expenses.expense_date",
+	"total":        "expenses.total",
+	"name":         "expenses.name",
+	"created_at":   "expenses.created_at",
+}
+
+// Scope applies the filter conditions (but not the ordering) to a query
+func (f ExpenseFilter) Scope(db *gorm.DB) *gorm.DB {
+	db = db.Where("expenses.user_id = ?", f.UserID)
+	if len(f.CategoryIDs) > 0 {
+		db = db.Where("expenses.category_id IN ?", f.CategoryIDs)
+	}
+	if len(f.AccountIDs) > 0 {
+		db = db.Where("expenses.account_id IN ?", f.AccountIDs)
+	}
+	if len(f.MerchantIDs) > 0 {
+		db = db.Where("expenses.merchant_id IN ?", f.MerchantIDs)
+	}
+	if len(f.Tags) > 0 {
+		db = db.Where("EXISTS (SELECT 1 FROM expense_tags JOIN tags ON tags.id = expense_tags.tag_id WHERE expense_tags.expense_id = expenses.id AND tags.name IN ?)", f.Tags)
+	}
+	if f.StartDate != nil {
+		db = db.Where("expenses.expense_date >= ?", *f.StartDate)
+	}
+	if f.EndDate != nil {
+		db = db.Where("expenses.expense_date <= ?", *f.EndDate)
+	}
+	if f.MinTotal != nil {
+		db = db.Where("expenses.total >= ?", *f.MinTotal)
+	}
+	if f.MaxTotal != nil {
+		db = db.Where("expenses.total <= ?", *f.MaxTotal)
+	}
+	if f.Search != "" {
+		db = db.Where("expenses.name ILIKE ?", "%"+escapeLike(f.Search)+"%")
+	}
+	return db
+}
+
+// OrderClause returns the ORDER BY clause for the filter, defaulting to newest first
+func (f ExpenseFilter) OrderClause() string {
+	column, ok := sortColumns[f.SortBy]
+	if !ok {
+		column = sortColumns["expense_date"]
+	}
+	direction := "DESC"
+	if strings.EqualFold(f.SortDir, "asc") {
+		direction = "ASC"
+	}
+	return column + " " + direction + ", expenses.id " + direction
+}
+
+// ValidSortField reports whether the field can be used in SortBy
+func ValidSortField(field string) bool {
+	_, ok := sortColumns[field]
+	return ok
+}
+
+// escapeLike escapes LIKE wildcards in user supplied search text
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 // AnalyticsQuery represents the query parameters for analytics
@@ -34,28 +184,69 @@ type AnalyticsQuery struct {
 	StartDate time.Time
 	EndDate   time.Time
 	UserID    uint
+	Tags      []string // optional, only expenses with any of these tags
+}
+
+// filter converts the analytics query into the equivalent expense filter
+func (q AnalyticsQuery) filter() ExpenseFilter {
+	return ExpenseFilter{
+		UserID:    q.UserID,
+		Tags:      q.Tags,
+		StartDate: &q.StartDate,
+		EndDate:   &q.EndDate,
+	}
 }
 
-// AnalyticsResult represents the analytics data
+// convertedTotal converts expenses.total into the base currency (bound to
+// the three ? placeholders), see currency.ConvertedSQL
+var convertedTotal = currency.ConvertedSQL("expenses.total", "expenses.currency", "expenses.expense_date")
+
+// TopMerchantsLimit is the number of merchants listed in analytics
+const TopMerchantsLimit = 10
+
+// AnalyticsResult represents the analytics data. Amounts are in Currency,
+// the user's base currency; expenses without a known rate are left out of
+// the totals and counted in UnconvertedCount.
 type AnalyticsResult struct {
-	TotalExpenses     float64           `json:"total_expenses"`
+	Currency          string            `json:"currency"`
+	UnconvertedCount  int64             `json:"unconverted_count"`
+	TotalExpenses     money.Amount      `json:"total_expenses"`
 	ExpenseCount      int64             `json:"expense_count"`
 	ByCategory        []CategoryExpense `json:"by_category"`
+	ByTag             []TagExpense      `json:"by_tag"`
+	TopMerchants      []MerchantExpense `json:"top_merchants"`
 	DailyExpenses     []DailyExpense    `json:"daily_expenses"`
-	AverageDailySpend float64           `json:"average_daily_spend"`
+	AverageDailySpend money.Amount      `json:"average_daily_spend"`
 	DateRange         DateRange         `json:"date_range"`
 }
 
 type CategoryExpense struct {
-	CategoryID   uint    `json:"category_id"`
-	CategoryName string  `json:"category_name"`
-	Total        float64 `json:"total"`
-	Count        int64   `json:"count"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Total        money.Amount `json:"total"`
+	Count        int64        `json:"count"`
+}
+
+// TagExpense is the spending on one tag. An expense with several tags counts
+// towards each of them, so these totals can add up to more than TotalExpenses.
+type TagExpense struct {
+	TagID   uint         `json:"tag_id"`
+	TagName string       `json:"tag_name"`
+	Total   money.Amount `json:"total"`
+	Count   int64        `json:"count"`
+}
+
+// MerchantExpense is the spending at one merchant
+type MerchantExpense struct {
+	MerchantID   uint         `json:"merchant_id"`
+	MerchantName string       `json:"merchant_name"`
+	Total        money.Amount `json:"total"`
+	Count        int64        `json:"count"`
 }
 
 type DailyExpense struct {
-	Date  string  `json:"date"`
-	Total float64 `json:"total"`
+	Date  string       `json:"date"`
+	Total money.Amount `json:"total"`
 }
 
 type DateRange struct {
@@ -63,15 +254,36 @@ type DateRange struct {
 	End   time.Time `json:"end"`
 }
 
-// Create creates a new expense
-func (s *Service) Create(userID uint, input CreateExpenseInput) (*models.Expense, error) {
-	// Verify category exists
-	var category models.Category
-	if err := s.db.First(&category, input.CategoryID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("category not found")
+// Create creates a new expense. It is saved even if it looks like a
+// duplicate; the likely duplicates are returned as warnings.
+func (s *Service) Create(userID uint, input CreateExpenseInput) (*models.Expense, []DuplicateWarning, error) {
+	if err := s.checkCategory(userID, input.CategoryID); err != nil {
+		return nil, nil, err
+	}
+
+	currencyCode, err := s.resolveCurrency(userID, input.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reject re-imports of the same external record
+	if input.ExternalID != nil {
+		var count int64
+		s.db.Unscoped().Model(&models.Expense{}).
+			Where("user_id = ? AND external_id = ?", userID, *input.ExternalID).
+			Count(&count)
+		if count > 0 {
+			return nil, nil, errors.New("expense with this external_id already exists")
+		}
+	}
+
+	if input.AccountID != nil && *input.AccountID == 0 {
+		input.AccountID = nil
+	}
+	if input.AccountID != nil {
+		if err := account.Check(s.db, userID, *input.AccountID, currencyCode); err != nil {
+			return nil, nil, err
 		}
-		return nil, err
 	}
 
 	// Set expense date to now if not provided
@@ -79,6 +291,12 @@ func (s *Service) Create(userID uint, input CreateExpenseInput) (*models.Expense
 		input.ExpenseDate = time.Now()
 	}
 
+	input.Currency = currencyCode
+	warnings, err := s.FindDuplicates(userID, []CreateExpenseInput{input})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	expense := &models.Expense{
 		Name:        input.Name,
 		CategoryID:  input.CategoryID,
@@ -86,32 +304,253 @@ func (s *Service) Create(userID uint, input CreateExpenseInput) (*models.Expense
 		Unit:        input.Unit,
 		PerUnitCost: input.PerUnitCost,
 		ExpenseDate: input.ExpenseDate,
+		Currency:    currencyCode,
+		ExternalID:  input.ExternalID,
+		AccountID:   input.AccountID,
+		Notes:       input.Notes,
 	}
 
-	// Total is calculated automatically in BeforeSave hook
-	if err := s.db.Create(expense).Error; err != nil {
-		return nil, err
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := tag.FindOrCreate(tx, userID, input.Tags)
+		if err != nil {
+			return err
+		}
+		expense.Tags = tags
+
+		resolver, err := merchant.NewResolver(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := setMerchant(resolver, expense, input.Merchant); err != nil {
+			return err
+		}
+
+		// Total is calculated automatically in BeforeSave hook
+		if err := tx.Create(expense).Error; err != nil {
+			return err
+		}
+		return revision.Record(tx, models.RevisionCreate, &userID, nil, expense)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Load category relationship
-	s.db.Preload("Category").First(expense, expense.ID)
+	// Load category, tag, merchant and account relationships
+	s.db.Preload("Category").Preload("Tags").Preload("Merchant").Preload("Account").First(expense, expense.ID)
+
+	s.notifyChange(userID)
 
-	return expense, nil
+	return expense, warnings, nil
 }
 
-// GetByUser retrieves all expenses for a user
-func (s *Service) GetByUser(userID uint, limit, offset int) ([]models.Expense, int64, error) {
+// CreateBulk validates every input and creates all expenses in a single
+// transaction. If any row is invalid nothing is written and a
+// *BulkValidationError listing the failing rows is returned. Rows that look
+// like duplicates are created too and returned as warnings.
+func (s *Service) CreateBulk(userID uint, inputs []CreateExpenseInput) ([]models.Expense, []DuplicateWarning, error) {
+	// Load all referenced categories in one query
+	categoryIDs := make([]uint, 0, len(inputs))
+	for _, input := range inputs {
+		if input.CategoryID != 0 {
+			categoryIDs = append(categoryIDs, input.CategoryID)
+		}
+	}
+
+	knownCategories := make(map[uint]bool)
+	if len(categoryIDs) > 0 {
+		var categories []models.Category
+		// Only default categories and the user's own may be used
+		err := s.db.Where("id IN ? AND (user_id IS NULL OR user_id = ?)", categoryIDs, userID).Find(&categories).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, category := range categories {
+			knownCategories[category.ID] = true
+		}
+	}
+
+	// Find external IDs that were already imported
+	externalIDs := make([]string, 0)
+	for _, input := range inputs {
+		if input.ExternalID != nil {
+			externalIDs = append(externalIDs, *input.ExternalID)
+		}
+	}
+
+	seenExternalIDs := make(map[string]bool)
+	if len(externalIDs) > 0 {
+		var existing []string
+		err := s.db.Unscoped().Model(&models.Expense{}).
+			Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+			Pluck("external_id", &existing).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range existing {
+			seenExternalIDs[id] = true
+		}
+	}
+
+	baseCurrency, err := s.BaseCurrency(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Currencies of the user's accounts, to check the accounts rows are paid from
+	var accounts []models.Account
+	if err := s.db.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, nil, err
+	}
+	accountCurrencies := make(map[uint]string, len(accounts))
+	for _, a := range accounts {
+		accountCurrencies[a.ID] = a.Currency
+	}
+
+	var rowErrors []RowError
+	for i, input := range inputs {
+		if input.Currency == "" {
+			input.Currency = baseCurrency
+		}
+		code, validCurrency := currency.NormalizeCode(input.Currency)
+		inputs[i].Currency = code
+		tagNames, tagErr := tag.NormalizeNames(input.Tags)
+		inputs[i].Tags = tagNames
+		if input.AccountID != nil && *input.AccountID == 0 {
+			inputs[i].AccountID = nil
+		}
+		accountCurrency, knownAccount := "", true
+		if inputs[i].AccountID != nil {
+			accountCurrency, knownAccount = accountCurrencies[*inputs[i].AccountID]
+		}
+
+		switch {
+		case input.Name == "":
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "name is required"})
+		case input.CategoryID == 0:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "category_id is required"})
+		case input.Unit <= 0:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "unit must be positive"})
+		case input.PerUnitCost <= 0:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "per_unit_cost must be positive"})
+		case !validCurrency:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "invalid currency"})
+		case tagErr != nil:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: tagErr.Error()})
+		case !knownCategories[input.CategoryID]:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "category not found"})
+		case input.ExternalID != nil && seenExternalIDs[*input.ExternalID]:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "expense with this external_id already exists"})
+		case !knownAccount:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "account not found"})
+		case inputs[i].AccountID != nil && accountCurrency != code:
+			rowErrors = append(rowErrors, RowError{Index: i, Error: "currency must match the account currency"})
+		}
+		if input.ExternalID != nil {
+			seenExternalIDs[*input.ExternalID] = true
+		}
+	}
+	if len(rowErrors) > 0 {
+		return nil, nil, &BulkValidationError{Rows: rowErrors}
+	}
+
+	warnings, err := s.FindDuplicates(userID, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	expenses := make([]models.Expense, len(inputs))
+	for i, input := range inputs {
+		// Set expense date to now if not provided
+		if input.ExpenseDate.IsZero() {
+			input.ExpenseDate = now
+		}
+		expenses[i] = models.Expense{
+			Name:        input.Name,
+			CategoryID:  input.CategoryID,
+			UserID:      userID,
+			Unit:        input.Unit,
+			PerUnitCost: input.PerUnitCost,
+			ExpenseDate: input.ExpenseDate,
+			Currency:    input.Currency,
+			ExternalID:  input.ExternalID,
+			AccountID:   input.AccountID,
+			Notes:       input.Notes,
+		}
+	}
+
+	// Every tag used by any row, created once up front
+	var allTags []string
+	for _, input := range inputs {
+		allTags = append(allTags, input.Tags...)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := tag.FindOrCreate(tx, userID, allTags)
+		if err != nil {
+			return err
+		}
+		tagsByName := make(map[string]models.Tag, len(tags))
+		for _, t := range tags {
+			tagsByName[t.Name] = t
+		}
+
+		resolver, err := merchant.NewResolver(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Total is calculated automatically in BeforeSave hook
+		for i := range expenses {
+			for _, name := range inputs[i].Tags {
+				expenses[i].Tags = append(expenses[i].Tags, tagsByName[name])
+			}
+			if err := setMerchant(resolver, &expenses[i], inputs[i].Merchant); err != nil {
+				return err
+			}
+			if err := tx.Create(&expenses[i]).Error; err != nil {
+				return err
+			}
+			if err := revision.Record(tx, models.RevisionCreate, &userID, nil, &expenses[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Load category, tag, merchant and account relationships
+	ids := make([]uint, len(expenses))
+	for i, expense := range expenses {
+		ids[i] = expense.ID
+	}
+	s.db.Preload("Category").Preload("Tags").Preload("Merchant").Preload("Account").Where("id IN ?", ids).Order("id ASC").Find(&expenses)
+
+	if len(expenses) > 0 {
+		s.notifyChange(userID)
+	}
+
+	return expenses, warnings, nil
+}
+
+// GetByUser retrieves the expenses matching the filter
+func (s *Service) GetByUser(filter ExpenseFilter, limit, offset int) ([]models.Expense, int64, error) {
 	var expenses []models.Expense
 	var total int64
 
-	// Count total
-	s.db.Model(&models.Expense{}).Where("user_id = ?", userID).Count(&total)
+	// Count total matching the filter
+	s.db.Model(&models.Expense{}).Scopes(filter.Scope).Count(&total)
 
 	// Get paginated results
 	err := s.db.
 		Preload("Category").
-		Where("user_id = ?", userID).
-		Order("expense_date DESC").
+		Preload("Tags").
+		Preload("Merchant").
+		Preload("Account").
+		Scopes(filter.Scope).
+		Order(filter.OrderClause()).
 		Limit(limit).
 		Offset(offset).
 		Find(&expenses).Error
@@ -123,12 +562,62 @@ func (s *Service) GetByUser(userID uint, limit, offset int) ([]models.Expense, i
 	return expenses, total, nil
 }
 
+// GetByUserAfter retrieves the expenses matching the filter using keyset
+// pagination on (expense_date, id). It returns the cursor for the next page,
+// or an empty string when there are no more results.
+func (s *Service) GetByUserAfter(filter ExpenseFilter, limit int, cursor *pagination.Cursor) ([]models.Expense, string, error) {
+	if filter.SortBy != "" && filter.SortBy != "expense_date" {
+		return nil, "", errors.New("cursor pagination only supports sorting by expense_date")
+	}
+
+	query := s.db.
+		Preload("Category").
+		Preload("Tags").
+		Preload("Merchant").
+		Preload("Account").
+		Scopes(filter.Scope)
+
+	ascending := strings.EqualFold(filter.SortDir, "asc")
+	if cursor != nil {
+		if ascending {
+			query = query.Where("(expenses.expense_date, expenses.id) > (?, ?)", cursor.Time, cursor.ID)
+		} else {
+			query = query.Where("(expenses.expense_date, expenses.id) < (?, ?)", cursor.Time, cursor.ID)
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	var expenses []models.Expense
+	err := query.
+		Order(filter.OrderClause()).
+		Limit(limit + 1).
+		Find(&expenses).Error
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(expenses) > limit {
+		expenses = expenses[:limit]
+		last := expenses[len(expenses)-1]
+		nextCursor = pagination.Cursor{Time: last.ExpenseDate, ID: last.ID}.Encode()
+	}
+
+	return expenses, nextCursor, nil
+}
+
 // GetByID retrieves a single expense by ID
 func (s *Service) GetByID(id, userID uint) (*models.Expense, error) {
 	var expense models.Expense
 
 	err := s.db.
 		Preload("Category").
+		Preload("Tags").
+		Preload("Merchant").
+		Preload("Account").
+		Preload("Receipts").
+		Preload("Splits.User").
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&expense).Error
 
@@ -177,75 +666,396 @@ func (s *Service) GetDateRange(userID uint) (*DateRange, error) {
 	}, nil
 }
 
-// GetAnalytics generates analytics for expenses within a date range
-func (s *Service) GetAnalytics(query AnalyticsQuery) (*AnalyticsResult, error) {
-	result := &AnalyticsResult{
-		DateRange: DateRange{
-			Start: query.StartDate,
-			End:   query.EndDate,
-		},
+// Spending is what a user spent in their base currency, overall and per
+// category. Expenses without a known rate are left out of the totals and
+// counted in UnconvertedCount.
+type Spending struct {
+	Currency         string
+	Total            money.Amount
+	Count            int64
+	UnconvertedCount int64
+	ByCategory       []CategoryExpense
+}
+
+// GetSpending totals the expenses matching the query. It is the aggregation
+// behind GetAnalytics and budget tracking.
+func (s *Service) GetSpending(query AnalyticsQuery) (*Spending, error) {
+	baseCurrency, err := s.BaseCurrency(query.UserID)
+	if err != nil {
+		return nil, err
 	}
+	spending := &Spending{Currency: baseCurrency}
+	rateArgs := []interface{}{baseCurrency, baseCurrency, baseCurrency}
 
 	// Get total expenses and count
 	var totalSum struct {
-		Total float64
-		Count int64
+		Total       money.Amount
+		Count       int64
+		Unconverted int64
 	}
 
-	err := s.db.Model(&models.Expense{}).
-		Select("COALESCE(SUM(total), 0) as total, COUNT(*) as count").
-		Where("user_id = ? AND expense_date BETWEEN ? AND ?", query.UserID, query.StartDate, query.EndDate).
+	filter := query.filter()
+
+	err = s.db.Model(&models.Expense{}).
+		Select("COALESCE(SUM("+convertedTotal+"), 0) as total, COUNT(*) as count, COUNT(*) - COUNT("+convertedTotal+") as unconverted", append(append(rateArgs, rateArgs...), rateArgs...)...).
+		Scopes(filter.Scope).
 		Scan(&totalSum).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	result.TotalExpenses = totalSum.Total
-	result.ExpenseCount = totalSum.Count
+	spending.Total = totalSum.Total
+	spending.Count = totalSum.Count
+	spending.UnconvertedCount = totalSum.Unconverted
 
 	// Get expenses by category
 	err = s.db.Model(&models.Expense{}).
-		Select("categories.id as category_id, categories.name as category_name, COALESCE(SUM(expenses.total), 0) as total, COUNT(expenses.id) as count").
+		Select("categories.id as category_id, categories.name as category_name, COALESCE(SUM("+convertedTotal+"), 0) as total, COUNT(expenses.id) as count", rateArgs...).
 		Joins("LEFT JOIN categories ON categories.id = expenses.category_id").
-		Where("expenses.user_id = ? AND expenses.expense_date BETWEEN ? AND ?", query.UserID, query.StartDate, query.EndDate).
+		Scopes(filter.Scope).
 		Group("categories.id, categories.name").
 		Order("total DESC").
-		Scan(&result.ByCategory).Error
+		Scan(&spending.ByCategory).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Get daily expenses
+	return spending, nil
+}
+
+// GetAnalytics generates analytics for expenses within a date range
+func (s *Service) GetAnalytics(query AnalyticsQuery) (*AnalyticsResult, error) {
+	result := &AnalyticsResult{
+		DateRange: DateRange{
+			Start: query.StartDate,
+			End:   query.EndDate,
+		},
+	}
+
+	spending, err := s.GetSpending(query)
+	if err != nil {
+		return nil, err
+	}
+	result.Currency = spending.Currency
+	result.TotalExpenses = spending.Total
+	result.ExpenseCount = spending.Count
+	result.UnconvertedCount = spending.UnconvertedCount
+	result.ByCategory = spending.ByCategory
+
+	baseCurrency := spending.Currency
+	rateArgs := []interface{}{baseCurrency, baseCurrency, baseCurrency}
+	filter := query.filter()
+
+	// Get expenses by tag
 	err = s.db.Model(&models.Expense{}).
-		Select("DATE(expense_date) as date, COALESCE(SUM(total), 0) as total").
-		Where("user_id = ? AND expense_date BETWEEN ? AND ?", query.UserID, query.StartDate, query.EndDate).
-		Group("DATE(expense_date)").
-		Order("date ASC").
-		Scan(&result.DailyExpenses).Error
+		Select("tags.id as tag_id, tags.name as tag_name, COALESCE(SUM("+convertedTotal+"), 0) as total, COUNT(expenses.id) as count", rateArgs...).
+		Joins("JOIN expense_tags ON expense_tags.expense_id = expenses.id").
+		Joins("JOIN tags ON tags.id = expense_tags.tag_id").
+		Scopes(filter.Scope).
+		Group("tags.id, tags.name").
+		Order("total DESC").
+		Scan(&result.ByTag).Error
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Get the merchants with the highest spending
+	err = s.db.Model(&models.Expense{}).
+		Select("merchants.id as merchant_id, merchants.name as merchant_name, COALESCE(SUM("+convertedTotal+"), 0) as total, COUNT(expenses.id) as count", rateArgs...).
+		Joins("JOIN merchants ON merchants.id = expenses.merchant_id").
+		Scopes(filter.Scope).
+		Group("merchants.id, merchants.name").
+		Order("total DESC").
+		Limit(TopMerchantsLimit).
+		Scan(&result.TopMerchants).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Get daily expenses
+	result.DailyExpenses, err = s.dailySpending(filter, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate average daily spend
 	days := query.EndDate.Sub(query.StartDate).Hours() / 24
 	if days > 0 && result.TotalExpenses > 0 {
-		result.AverageDailySpend = result.TotalExpenses / days
+		result.AverageDailySpend = result.TotalExpenses.Div(days)
 	}
 
 	return result, nil
 }
 
-// Delete soft deletes an expense
-func (s *Service) Delete(id, userID uint) error {
-	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Expense{})
-	if result.Error != nil {
-		return result.Error
+// GetDailySpending totals the expenses matching the query per day, in the
+// user's base currency. Days without expenses are left out.
+func (s *Service) GetDailySpending(query AnalyticsQuery) ([]DailyExpense, error) {
+	baseCurrency, err := s.BaseCurrency(query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.dailySpending(query.filter(), baseCurrency)
+}
+
+// dailySpending is the daily aggregation behind GetAnalytics and GetDailySpending
+func (s *Service) dailySpending(filter ExpenseFilter, baseCurrency string) ([]DailyExpense, error) {
+	var daily []DailyExpense
+	err := s.db.Model(&models.Expense{}).
+		Select("DATE(expenses.expense_date) as date, COALESCE(SUM("+convertedTotal+"), 0) as total", baseCurrency, baseCurrency, baseCurrency).
+		Scopes(filter.Scope).
+		Group("DATE(expenses.expense_date)").
+		Order("date ASC").
+		Scan(&daily).Error
+	if err != nil {
+		return nil, err
+	}
+	return daily, nil
+}
+
+// Update applies a partial update to an expense owned by the user. When
+// expected is set, the update only applies if the expense was last updated
+// at expected.
+func (s *Service) Update(id, userID uint, input UpdateExpenseInput, expected *time.Time) (*models.Expense, error) {
+	var expense models.Expense
+	err := s.db.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&expense).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("expense not found")
+		}
+		return nil, err
+	}
+	before := expense
+
+	if input.Name != nil {
+		expense.Name = *input.Name
+	}
+	if input.Notes != nil {
+		expense.Notes = *input.Notes
+	}
+	if input.CategoryID != nil {
+		if err := s.checkCategory(userID, *input.CategoryID); err != nil {
+			return nil, err
+		}
+		expense.CategoryID = *input.CategoryID
+	}
+	if input.Unit != nil {
+		expense.Unit = *input.Unit
+	}
+	if input.PerUnitCost != nil {
+		expense.PerUnitCost = *input.PerUnitCost
+	}
+	if input.ExpenseDate != nil {
+		expense.ExpenseDate = *input.ExpenseDate
+	}
+	if input.Currency != nil {
+		code, ok := currency.NormalizeCode(*input.Currency)
+		if !ok {
+			return nil, errors.New("invalid currency")
+		}
+		expense.Currency = code
+	}
+	if input.AccountID != nil {
+		if *input.AccountID == 0 {
+			expense.AccountID = nil
+		} else {
+			expense.AccountID = input.AccountID
+		}
+	}
+	// Recheck the account when it or the currency changes
+	if expense.AccountID != nil && (input.AccountID != nil || input.Currency != nil) {
+		if err := account.Check(s.db, userID, *expense.AccountID, expense.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only write over the version the client last saw
+		if expected != nil {
+			result := tx.Model(&models.Expense{}).
+				Where("id = ? AND user_id = ? AND updated_at = ?", expense.ID, userID, *expected).
+				UpdateColumn("updated_at", time.Now())
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("expense was modified")
+			}
+		}
+
+		if input.Merchant != nil {
+			resolver, err := merchant.NewResolver(tx, userID)
+			if err != nil {
+				return err
+			}
+			if err := setMerchant(resolver, &expense, *input.Merchant); err != nil {
+				return err
+			}
+		}
+
+		// Total is recalculated in BeforeSave hook
+		if err := tx.Omit(clause.Associations).Save(&expense).Error; err != nil {
+			return err
+		}
+		// Keep the shares of a shared expense in line with its new total
+		if err := split.Rebalance(tx, &expense); err != nil {
+			return err
+		}
+		if input.Tags != nil {
+			tags, err := tag.FindOrCreate(tx, userID, *input.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&expense).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+			expense.Tags = tags
+		}
+
+		return revision.Record(tx, models.RevisionUpdate, &userID, &before, &expense)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Load category, tag, merchant and account relationships
+	s.db.Preload("Category").Preload("Tags").Preload("Merchant").Preload("Account").First(&expense, expense.ID)
+
+	s.notifyChange(userID)
+
+	return &expense, nil
+}
+
+// ExportRow is a flattened expense with its category name joined in
+type ExportRow struct {
+	ID           uint         `json:"id"`
+	ExpenseDate  time.Time    `json:"expense_date"`
+	Name         string       `json:"name"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Unit         float64      `json:"unit"`
+	PerUnitCost  money.Amount `json:"per_unit_cost"`
+	Total        money.Amount `json:"total"`
+	Currency     string       `json:"currency"`
+	MerchantName string       `json:"merchant_name"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// Export streams the expenses matching the filter to fn one row at a time,
+// so large exports are never loaded into memory at once
+func (s *Service) Export(filter ExpenseFilter, fn func(row ExportRow) error) error {
+	rows, err := s.db.Model(&models.Expense{}).
+		Select("expenses.id, expenses.expense_date, expenses.name, expenses.category_id, categories.name as category_name, expenses.unit, expenses.per_unit_cost, expenses.total, expenses.currency, COALESCE(merchants.name, '') as merchant_name, expenses.created_at").
+		Joins("LEFT JOIN categories ON categories.id = expenses.category_id").
+		Joins("LEFT JOIN merchants ON merchants.id = expenses.merchant_id").
+		Scopes(filter.Scope).
+		Order(filter.OrderClause()).
+		Rows()
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("expense not found")
+	defer rows.Close()
+
+	for rows.Next() {
+		var row ExportRow
+		if err := s.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
 	}
+
+	return rows.Err()
+}
+
+// setMerchant links the expense to the merchant of a raw merchant string,
+// or unlinks it when the string is blank
+func setMerchant(resolver *merchant.Resolver, expense *models.Expense, raw string) error {
+	found, err := resolver.Resolve(raw)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		expense.MerchantID = nil
+		expense.RawMerchant = nil
+		return nil
+	}
+	raw = merchant.TruncateRaw(raw)
+	expense.MerchantID = &found.ID
+	expense.RawMerchant = &raw
 	return nil
 }
+
+// checkCategory verifies the category exists and the user may use it,
+// i.e. it is a default category or one of their own
+func (s *Service) checkCategory(userID, categoryID uint) error {
+	var category models.Category
+	err := s.db.Where("id = ? AND (user_id IS NULL OR user_id = ?)", categoryID, userID).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// BaseCurrency returns the currency the user's analytics are reported in
+func (s *Service) BaseCurrency(userID uint) (string, error) {
+	var user models.User
+	if err := s.db.Select("base_currency").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	if user.BaseCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return user.BaseCurrency, nil
+}
+
+// resolveCurrency validates a currency code, defaulting to the user's base currency
+func (s *Service) resolveCurrency(userID uint, code string) (string, error) {
+	if code == "" {
+		return s.BaseCurrency(userID)
+	}
+	code, ok := currency.NormalizeCode(code)
+	if !ok {
+		return "", errors.New("invalid currency")
+	}
+	return code, nil
+}
+
+// Delete soft deletes an expense (it can be restored from the trash). When
+// expected is set, the expense is only deleted if it was last updated at
+// expected.
+func (s *Service) Delete(id, userID uint, expected *time.Time) error {
+	var expense models.Expense
+	err := s.db.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&expense).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("expense not found")
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if expected != nil {
+			query = tx.Where("updated_at = ?", *expected)
+		}
+		result := query.Delete(&expense)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if expected != nil {
+				return errors.New("expense was modified")
+			}
+			return errors.New("expense not found")
+		}
+		return revision.Record(tx, models.RevisionDelete, &userID, &expense, nil)
+	})
+}
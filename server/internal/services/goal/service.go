@@ -0,0 +1,376 @@
+package goal
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"github.com/parvejmia9/minflow/server/internal/services/income"
+	"gorm.io/gorm"
+)
+
+// Projection windows, in days of recent activity
+const (
+	DefaultProjectionDays = 90
+	MaxProjectionDays     = 365
+)
+
+// Service handles savings goal business logic
+type Service struct {
+	db             *gorm.DB
+	expenseService *expense.Service
+	incomeService  *income.Service
+}
+
+// NewService creates a new goal service instance. Projections use the
+// expense and income services to measure the user's recent pace.
+func NewService(db *gorm.DB, expenseService *expense.Service, incomeService *income.Service) *Service {
+	return &Service{
+		db:             db,
+		expenseService: expenseService,
+		incomeService:  incomeService,
+	}
+}
+
+// CreateGoalInput represents the input for creating a goal
+type CreateGoalInput struct {
+	Name         string       `json:"name" validate:"required"`
+	TargetAmount money.Amount `json:"target_amount" validate:"required,gt=0"`
+	Deadline     *time.Time   `json:"deadline"`
+	Note         string       `json:"note"`
+}
+
+// UpdateGoalInput represents the input for partially updating a goal.
+// Nil fields are left unchanged.
+type UpdateGoalInput struct {
+	Name          *string       `json:"name"`
+	TargetAmount  *money.Amount `json:"target_amount"`
+	Deadline      *time.Time    `json:"deadline"`
+	ClearDeadline bool          `json:"clear_deadline"`
+	Note          *string       `json:"note"`
+}
+
+// ContributionInput represents the input for recording a contribution
+type ContributionInput struct {
+	Amount money.Amount `json:"amount" validate:"required,gt=0"`
+	Date   time.Time    `json:"date"` // defaults to now
+	Note   string       `json:"note"`
+}
+
+// GoalSummary is a goal with its progress
+type GoalSummary struct {
+	models.Goal
+	Saved     money.Amount `json:"saved"`
+	Remaining money.Amount `json:"remaining"`
+	Percent   float64      `json:"percent"` // saved as a percentage of the target
+	Completed bool         `json:"completed"`
+}
+
+// Projection estimates when a goal will be reached if the user keeps saving
+// at the pace of the last WindowDays days. Amounts are in Currency, the
+// user's base currency.
+type Projection struct {
+	Goal                 GoalSummary   `json:"goal"`
+	Currency             string        `json:"currency"`
+	WindowDays           int           `json:"window_days"`
+	DailyIncome          money.Amount  `json:"daily_income"`
+	DailySpending        money.Amount  `json:"daily_spending"`
+	DailySavings         money.Amount  `json:"daily_savings"`          // income minus spending, the pace the estimate uses
+	DaysToComplete       *int          `json:"days_to_complete"`       // nil when nothing is being saved
+	ProjectedDate        *time.Time    `json:"projected_date"`         // nil when nothing is being saved
+	RequiredDailySavings *money.Amount `json:"required_daily_savings"` // needed to meet the deadline, nil without one
+	OnTrack              bool          `json:"on_track"`               // completed, or projected to finish by the deadline
+}
+
+// GetByUser retrieves all goals of a user with their progress
+func (s *Service) GetByUser(userID uint) ([]GoalSummary, error) {
+	var goals []GoalSummary
+
+	err := s.summaries().
+		Where("goals.user_id = ?", userID).
+		Order("goals.created_at DESC").
+		Scan(&goals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range goals {
+		goals[i].fillProgress()
+	}
+
+	return goals, nil
+}
+
+// GetByID retrieves one of the user's goals with its progress
+func (s *Service) GetByID(id, userID uint) (*GoalSummary, error) {
+	var goals []GoalSummary
+
+	err := s.summaries().
+		Where("goals.id = ? AND goals.user_id = ?", id, userID).
+		Scan(&goals).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, errors.New("goal not found")
+	}
+
+	goals[0].fillProgress()
+	return &goals[0], nil
+}
+
+// Create creates a new goal
+func (s *Service) Create(userID uint, input CreateGoalInput) (*GoalSummary, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if input.TargetAmount <= 0 {
+		return nil, errors.New("target amount must be positive")
+	}
+
+	goal := &models.Goal{
+		UserID:       userID,
+		Name:         name,
+		TargetAmount: input.TargetAmount,
+		Deadline:     input.Deadline,
+		Note:         input.Note,
+	}
+	if err := s.db.Create(goal).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(goal.ID, userID)
+}
+
+// Update applies a partial update to one of the user's goals
+func (s *Service) Update(id, userID uint, input UpdateGoalInput) (*GoalSummary, error) {
+	goal, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		goal.Name = name
+	}
+	if input.TargetAmount != nil {
+		if *input.TargetAmount <= 0 {
+			return nil, errors.New("target amount must be positive")
+		}
+		goal.TargetAmount = *input.TargetAmount
+	}
+	if input.ClearDeadline {
+		goal.Deadline = nil
+	} else if input.Deadline != nil {
+		goal.Deadline = input.Deadline
+	}
+	if input.Note != nil {
+		goal.Note = *input.Note
+	}
+
+	if err := s.db.Save(goal).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(goal.ID, userID)
+}
+
+// Delete deletes one of the user's goals along with its contributions
+func (s *Service) Delete(id, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Goal{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("goal not found")
+		}
+		return tx.Where("goal_id = ?", id).Delete(&models.GoalContribution{}).Error
+	})
+}
+
+// GetContributions retrieves the contributions to one of the user's goals,
+// newest first
+func (s *Service) GetContributions(goalID, userID uint) ([]models.GoalContribution, error) {
+	if _, err := s.find(goalID, userID); err != nil {
+		return nil, err
+	}
+
+	var contributions []models.GoalContribution
+	err := s.db.Where("goal_id = ?", goalID).
+		Order("date DESC, id DESC").
+		Find(&contributions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return contributions, nil
+}
+
+// AddContribution records money put towards one of the user's goals
+func (s *Service) AddContribution(goalID, userID uint, input ContributionInput) (*models.GoalContribution, error) {
+	if _, err := s.find(goalID, userID); err != nil {
+		return nil, err
+	}
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	if input.Date.IsZero() {
+		input.Date = time.Now()
+	}
+
+	contribution := &models.GoalContribution{
+		GoalID: goalID,
+		UserID: userID,
+		Amount: input.Amount,
+		Date:   input.Date,
+		Note:   input.Note,
+	}
+	if err := s.db.Create(contribution).Error; err != nil {
+		return nil, err
+	}
+
+	return contribution, nil
+}
+
+// DeleteContribution deletes a contribution from one of the user's goals
+func (s *Service) DeleteContribution(goalID, contributionID, userID uint) error {
+	result := s.db.Where("id = ? AND goal_id = ? AND user_id = ?", contributionID, goalID, userID).
+		Delete(&models.GoalContribution{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("contribution not found")
+	}
+	return nil
+}
+
+// GetProjection estimates when one of the user's goals will be reached,
+// based on their income and spending over the last days days
+func (s *Service) GetProjection(id, userID uint, now time.Time, days int) (*Projection, error) {
+	if days <= 0 {
+		days = DefaultProjectionDays
+	}
+	if days > MaxProjectionDays {
+		days = MaxProjectionDays
+	}
+
+	goal, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currencyCode, err := s.expenseService.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The window ends now and covers days whole days
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, 1-days)
+
+	daily, err := s.expenseService.GetDailySpending(expense.AnalyticsQuery{
+		UserID:    userID,
+		StartDate: start,
+		EndDate:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var spent money.Amount
+	for _, day := range daily {
+		spent += day.Total
+	}
+
+	earned, err := s.incomeService.GetTotal(userID, start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := project(*goal, earned, spent, days, today)
+	projection.Currency = currencyCode
+
+	return projection, nil
+}
+
+// project estimates when goal will be reached from what was earned and
+// spent over the last days days, counting from today
+func project(goal GoalSummary, earned, spent money.Amount, days int, today time.Time) *Projection {
+	projection := &Projection{
+		Goal:          goal,
+		WindowDays:    days,
+		DailyIncome:   earned.Div(float64(days)),
+		DailySpending: spent.Div(float64(days)),
+	}
+	projection.DailySavings = projection.DailyIncome - projection.DailySpending
+
+	if goal.Completed {
+		zero := 0
+		projection.DaysToComplete = &zero
+		projection.ProjectedDate = &today
+		projection.OnTrack = true
+		return projection
+	}
+
+	if projection.DailySavings > 0 {
+		needed := int(math.Ceil(float64(goal.Remaining) / float64(projection.DailySavings)))
+		projected := today.AddDate(0, 0, needed)
+		projection.DaysToComplete = &needed
+		projection.ProjectedDate = &projected
+	}
+
+	if goal.Deadline != nil {
+		left := int(math.Ceil(goal.Deadline.Sub(today).Hours() / 24))
+		required := goal.Remaining
+		if left > 0 {
+			required = goal.Remaining.Div(float64(left))
+		}
+		projection.RequiredDailySavings = &required
+		projection.OnTrack = projection.ProjectedDate != nil && !projection.ProjectedDate.After(*goal.Deadline)
+	} else {
+		projection.OnTrack = projection.ProjectedDate != nil
+	}
+
+	return projection
+}
+
+// summaries selects goals with the sum of their contributions as saved
+func (s *Service) summaries() *gorm.DB {
+	return s.db.Model(&models.Goal{}).
+		Select("goals.*, COALESCE((SELECT SUM(goal_contributions.amount) FROM goal_contributions WHERE goal_contributions.goal_id = goals.id), 0) as saved")
+}
+
+// find loads one of the user's goals without its progress
+func (s *Service) find(id, userID uint) (*models.Goal, error) {
+	var goal models.Goal
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&goal).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("goal not found")
+		}
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// fillProgress derives remaining, percent and completed from saved
+func (g *GoalSummary) fillProgress() {
+	g.Remaining = g.TargetAmount - g.Saved
+	if g.Remaining < 0 {
+		g.Remaining = 0
+	}
+	g.Completed = g.Saved >= g.TargetAmount
+	if g.TargetAmount > 0 {
+		g.Percent = math.Round(float64(g.Saved)/float64(g.TargetAmount)*10000) / 100
+	}
+}
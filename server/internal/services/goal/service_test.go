@@ -0,0 +1,146 @@
+package goal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+func summary(target, saved money.Amount, deadline *time.Time) GoalSummary {
+	g := GoalSummary{Goal: models.Goal{TargetAmount: target, Deadline: deadline}, Saved: saved}
+	g.fillProgress()
+	return g
+}
+
+func TestFillProgress(t *testing.T) {
+	tests := []struct {
+		name          string
+		target, saved money.Amount
+		wantRemaining money.Amount
+		wantPercent   float64
+		wantCompleted bool
+	}{
+		{"nothing saved", 100000, 0, 100000, 0, false},
+		{"a third", 30000, 10000, 20000, 33.33, false},
+		{"two thirds", 30000, 20000, 10000, 66.67, false},
+		{"reached", 50000, 50000, 0, 100, true},
+		{"over the target", 50000, 60000, 0, 120, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := summary(tt.target, tt.saved, nil)
+			if g.Remaining != tt.wantRemaining || g.Percent != tt.wantPercent || g.Completed != tt.wantCompleted {
+				t.Errorf("fillProgress() = remaining %s, %v%%, completed %v; want %s, %v%%, %v",
+					g.Remaining, g.Percent, g.Completed, tt.wantRemaining, tt.wantPercent, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	today := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		d := today.AddDate(0, 0, days)
+		return &d
+	}
+	amount := func(a money.Amount) *money.Amount { return &a }
+
+	tests := []struct {
+		name          string
+		goal          GoalSummary
+		earned, spent money.Amount
+		wantSavings   money.Amount
+		wantDays      *int
+		wantRequired  *money.Amount
+		wantOnTrack   bool
+	}{
+		{
+			// 9000.00 earned and 4500.00 spent over 90 days save 50.00 a day
+			name:   "no deadline",
+			goal:   summary(100000, 40000, nil),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(12), wantOnTrack: true,
+		},
+		{
+			name:   "partial day rounds up",
+			goal:   summary(100001, 40000, nil),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(13), wantOnTrack: true,
+		},
+		{
+			name:   "deadline met",
+			goal:   summary(100000, 40000, at(45)),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(12), wantRequired: amount(1333), wantOnTrack: true,
+		},
+		{
+			name:   "finishing on the deadline",
+			goal:   summary(100000, 40000, at(12)),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(12), wantRequired: amount(5000), wantOnTrack: true,
+		},
+		{
+			name:   "deadline missed",
+			goal:   summary(100000, 40000, at(10)),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(12), wantRequired: amount(6000),
+		},
+		{
+			name:   "spending more than earned",
+			goal:   summary(100000, 40000, at(30)),
+			earned: 450000, spent: 900000,
+			wantSavings: -5000, wantRequired: amount(2000),
+		},
+		{
+			name:   "deadline passed",
+			goal:   summary(100000, 40000, at(-3)),
+			earned: 900000, spent: 450000,
+			wantSavings: 5000, wantDays: intPtr(12), wantRequired: amount(60000),
+		},
+		{
+			name:   "completed",
+			goal:   summary(100000, 100000, at(-3)),
+			earned: 0, spent: 450000,
+			wantSavings: -5000, wantDays: intPtr(0), wantOnTrack: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := project(tt.goal, tt.earned, tt.spent, 90, today)
+
+			if p.DailySavings != tt.wantSavings {
+				t.Errorf("DailySavings = %s, want %s", p.DailySavings, tt.wantSavings)
+			}
+			if (p.DaysToComplete == nil) != (tt.wantDays == nil) || (p.DaysToComplete != nil && *p.DaysToComplete != *tt.wantDays) {
+				t.Errorf("DaysToComplete = %v, want %v", deref(p.DaysToComplete), deref(tt.wantDays))
+			}
+			if p.DaysToComplete != nil && !p.ProjectedDate.Equal(today.AddDate(0, 0, *p.DaysToComplete)) {
+				t.Errorf("ProjectedDate = %v, want %d days from today", p.ProjectedDate, *p.DaysToComplete)
+			}
+			if p.DaysToComplete == nil && p.ProjectedDate != nil {
+				t.Errorf("ProjectedDate = %v, want none", p.ProjectedDate)
+			}
+			if (p.RequiredDailySavings == nil) != (tt.wantRequired == nil) || (p.RequiredDailySavings != nil && *p.RequiredDailySavings != *tt.wantRequired) {
+				t.Errorf("RequiredDailySavings = %v, want %v", p.RequiredDailySavings, tt.wantRequired)
+			}
+			if p.OnTrack != tt.wantOnTrack {
+				t.Errorf("OnTrack = %v, want %v", p.OnTrack, tt.wantOnTrack)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func deref(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartPurger purges expired keys immediately and then on every tick of the
+// given interval, until the context is cancelled. It blocks, so run it in
+// its own goroutine.
+func (s *Service) StartPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(time.Now())
+		if err != nil {
+			log.Println("Warning: Failed to purge idempotency keys:", err)
+		}
+		if purged > 0 {
+			log.Printf("Purged %d expired idempotency key(s)", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
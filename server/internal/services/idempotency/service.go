@@ -0,0 +1,127 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTTL is how long responses are kept for replay
+const DefaultTTL = 24 * time.Hour
+
+// MaxKeyLength is the longest accepted Idempotency-Key
+const MaxKeyLength = 255
+
+// ClaimLease is how long a claimed key stays in progress without a stored
+// response. A claim older than that was left behind by a request that
+// never finished (e.g. the server crashed), and the key can be claimed
+// again. It must be longer than any request takes.
+const ClaimLease = 5 * time.Minute
+
+// Service stores responses by idempotency key
+type Service struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewService creates a new idempotency service instance. ttl is how long a
+// key and its response are kept (DefaultTTL when zero); after that the key
+// can be used for a new request.
+func NewService(db *gorm.DB, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{
+		db:  db,
+		ttl: ttl,
+	}
+}
+
+// RequestHash fingerprints a request, so a key reused for a different
+// request can be told apart from a retry
+func RequestHash(method, url string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + url + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Claim reserves a key for a new request. It returns nil when the request
+// should be handled, or the stored record when it is a retry of a
+// completed request whose response should be replayed.
+func (s *Service) Claim(userID uint, key, requestHash string) (*models.IdempotencyKey, error) {
+	if len(key) > MaxKeyLength {
+		return nil, errors.New("idempotency key is too long")
+	}
+
+	now := time.Now()
+
+	// An expired key, or one abandoned while in progress, can be used again
+	err := s.db.Where("user_id = ? AND key = ?", userID, key).
+		Where("expires_at <= ? OR (status_code = 0 AND created_at <= ?)", now, now.Add(-ClaimLease)).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	err = s.db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released by a failed first attempt in the meantime
+			return nil, errors.New("request with this idempotency key is in progress")
+		}
+		return nil, err
+	}
+	if existing.RequestHash != requestHash {
+		return nil, errors.New("idempotency key was used for a different request")
+	}
+	if existing.StatusCode == 0 {
+		return nil, errors.New("request with this idempotency key is in progress")
+	}
+
+	return &existing, nil
+}
+
+// Complete stores the response to a claimed request for replay
+func (s *Service) Complete(userID uint, key string, statusCode int, contentType string, body []byte) error {
+	return s.db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+		}).Error
+}
+
+// Release frees a claimed key without storing a response, so the request
+// can be retried, e.g. after a server error
+func (s *Service) Release(userID uint, key string) error {
+	return s.db.Where("user_id = ? AND key = ? AND status_code = 0", userID, key).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeExpired deletes keys whose TTL has passed and returns how many
+func (s *Service) PurgeExpired(now time.Time) (int64, error) {
+	result := s.db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,369 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+)
+
+// BankTransaction is a single transaction read from a bank statement
+type BankTransaction struct {
+	ExternalID string
+	Date       time.Time
+	Amount     money.Amount // negative for debits
+	Payee      string
+	Memo       string
+	Category   string // category hint from the file, if any
+	Currency   string // empty when the statement doesn't say
+	Line       int
+}
+
+// ParseOFX reads the transactions of an OFX/QFX statement. Both the SGML
+// (OFX 1.x, unclosed tags) and XML (OFX 2.x) dialects are accepted.
+func ParseOFX(r io.Reader) ([]BankTransaction, []LineError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := string(data)
+	start := strings.Index(strings.ToUpper(content), "<OFX>")
+	if start < 0 {
+		return nil, nil, InvalidFileError("not an OFX file (missing <OFX> element)")
+	}
+
+	var (
+		transactions []BankTransaction
+		lineErrors   []LineError
+		current      map[string]string
+		currentLine  int
+		accountID    string
+		currencyCode string
+	)
+
+	line := 1 + strings.Count(content[:start], "\n")
+	for _, token := range strings.Split(content[start:], "<")[1:] {
+		end := strings.Index(token, ">")
+		if end < 0 {
+			line += strings.Count(token, "\n")
+			continue
+		}
+		tag := strings.ToUpper(strings.TrimSpace(token[:end]))
+		value := strings.TrimSpace(token[end+1:])
+
+		switch {
+		case tag == "STMTTRN":
+			current = make(map[string]string)
+			currentLine = line
+		case tag == "/STMTTRN":
+			if current != nil {
+				txn, err := ofxTransaction(current, accountID)
+				txn.Currency = currencyCode
+				if err != nil {
+					lineErrors = append(lineErrors, LineError{Line: currentLine, Error: err.Error()})
+				} else {
+					txn.Line = currentLine
+					transactions = append(transactions, txn)
+				}
+			}
+			current = nil
+		case tag == "ACCTID":
+			accountID = value
+		case tag == "CURDEF":
+			currencyCode, _ = currency.NormalizeCode(value)
+		case current != nil && !strings.HasPrefix(tag, "/") && value != "":
+			current[tag] = value
+		}
+
+		line += strings.Count(token, "\n")
+	}
+
+	return transactions, lineErrors, nil
+}
+
+// ofxTransaction converts the fields of a STMTTRN aggregate
+func ofxTransaction(fields map[string]string, accountID string) (BankTransaction, error) {
+	var txn BankTransaction
+
+	fitID := fields["FITID"]
+	if fitID == "" {
+		return txn, errors.New("transaction has no FITID")
+	}
+	txn.ExternalID = "ofx:" + accountID + ":" + fitID
+
+	amount, err := parseAmount(fields["TRNAMT"])
+	if err != nil {
+		return txn, fmt.Errorf("invalid TRNAMT %q", fields["TRNAMT"])
+	}
+	txn.Amount = amount
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return txn, fmt.Errorf("invalid DTPOSTED %q", fields["DTPOSTED"])
+	}
+	txn.Date = date
+
+	txn.Payee = decodeOFXText(fields["NAME"])
+	if txn.Payee == "" {
+		txn.Payee = decodeOFXText(fields["PAYEE"])
+	}
+	txn.Memo = decodeOFXText(fields["MEMO"])
+
+	return txn, nil
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[gmt offset[:tz name]]]
+func parseOFXDate(value string) (time.Time, error) {
+	if i := strings.Index(value, "["); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, "."); i >= 0 {
+		value = value[:i]
+	}
+
+	switch len(value) {
+	case 8:
+		return time.Parse("20060102", value)
+	case 12:
+		return time.Parse("200601021504", value)
+	case 14:
+		return time.Parse("20060102150405", value)
+	}
+	return time.Time{}, errors.New("invalid OFX date")
+}
+
+// decodeOFXText unescapes the entities allowed in OFX text values
+func decodeOFXText(value string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(value)
+}
+
+// defaultQIFDateLayouts are tried in order when no date format is given
+var defaultQIFDateLayouts = []string{"1/2/2006", "1/2/06", "2006-01-02"}
+
+// ParseQIF reads the transactions of a QIF file. QIF has no transaction IDs,
+// so a stable ID is derived from the transaction contents.
+func ParseQIF(r io.Reader, dateFormat string) ([]BankTransaction, []LineError, error) {
+	layouts := defaultQIFDateLayouts
+	if dateFormat != "" {
+		layout, err := ParseDateFormat(dateFormat)
+		if err != nil {
+			return nil, nil, InvalidFileError(err.Error())
+		}
+		layouts = []string{layout}
+	}
+
+	var (
+		transactions []BankTransaction
+		lineErrors   []LineError
+		fields       = make(map[byte]string)
+		startLine    int
+		occurrences  = make(map[string]int)
+	)
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+
+		if text[0] != '^' {
+			if len(fields) == 0 {
+				startLine = lineNumber
+			}
+			// Split transactions (S/E/$) are not needed, keep the first value of each code
+			if _, ok := fields[text[0]]; !ok {
+				fields[text[0]] = strings.TrimSpace(text[1:])
+			}
+			continue
+		}
+
+		// Records without a date (e.g. !Account blocks) are not transactions
+		if _, ok := fields['D']; ok {
+			txn, err := qifTransaction(fields, layouts)
+			if err != nil {
+				lineErrors = append(lineErrors, LineError{Line: startLine, Error: err.Error()})
+			} else {
+				// Identical transactions on the same day are told apart by their order
+				key := txn.Date.Format("2006-01-02") + "|" + txn.Amount.String() + "|" + txn.Payee + "|" + txn.Memo + "|" + fields['N']
+				occurrences[key]++
+				sum := sha1.Sum([]byte(key + "|" + strconv.Itoa(occurrences[key])))
+				txn.ExternalID = "qif:" + hex.EncodeToString(sum[:])
+				txn.Line = startLine
+				transactions = append(transactions, txn)
+			}
+		}
+		fields = make(map[byte]string)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return transactions, lineErrors, nil
+}
+
+// qifTransaction converts the fields of a single QIF record
+func qifTransaction(fields map[byte]string, layouts []string) (BankTransaction, error) {
+	var txn BankTransaction
+
+	amountText, ok := fields['T']
+	if !ok {
+		amountText = fields['U']
+	}
+	amount, err := parseAmount(amountText)
+	if err != nil {
+		return txn, fmt.Errorf("invalid amount %q", amountText)
+	}
+	txn.Amount = amount
+
+	dateText := strings.ReplaceAll(strings.ReplaceAll(fields['D'], "'", "/"), " ", "")
+	parsed := false
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, dateText); err == nil {
+			txn.Date = date
+			parsed = true
+			break
+		}
+	}
+	if !parsed {
+		return txn, fmt.Errorf("invalid date %q", fields['D'])
+	}
+
+	txn.Payee = fields['P']
+	txn.Memo = fields['M']
+	txn.Category = fields['L']
+
+	return txn, nil
+}
+
+// PrepareBankImport converts bank transactions into expense rows. Only
+// debits are imported; transactions imported before are counted as skipped.
+func (s *Service) PrepareBankImport(userID uint, transactions []BankTransaction, lineErrors []LineError) (*ImportResult, error) {
+	result := &ImportResult{DryRun: true, Rows: []ImportRow{}, Errors: lineErrors}
+	if result.Errors == nil {
+		result.Errors = []LineError{}
+	}
+
+	// Find transactions that were already imported
+	externalIDs := make([]string, 0, len(transactions))
+	for _, txn := range transactions {
+		externalIDs = append(externalIDs, txn.ExternalID)
+	}
+	imported := make(map[string]bool)
+	if len(externalIDs) > 0 {
+		var existing []string
+		err := s.db.Unscoped().Model(&models.Expense{}).
+			Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+			Pluck("external_id", &existing).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range existing {
+			imported[id] = true
+		}
+	}
+
+	categories, err := s.loadCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := s.loadPayeeCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	fallback, hasFallback := categories.findByName("Other")
+
+	for _, txn := range transactions {
+		// Credits (refunds, salary, transfers in) are not expenses
+		if txn.Amount >= 0 {
+			continue
+		}
+		if imported[txn.ExternalID] {
+			result.Skipped++
+			continue
+		}
+		imported[txn.ExternalID] = true
+
+		name := txn.Payee
+		if name == "" {
+			name = txn.Memo
+		}
+		if name == "" {
+			result.Errors = append(result.Errors, LineError{Line: txn.Line, Error: "transaction has no payee or memo"})
+			continue
+		}
+
+		// Category from the file, then from the payee's history, then "Other"
+		category, ok := categories.findByName(txn.Category)
+		if !ok {
+			if id, found := payees[strings.ToLower(name)]; found {
+				category, ok = categories.findByID(id)
+			}
+		}
+		if !ok {
+			if !hasFallback {
+				result.Errors = append(result.Errors, LineError{Line: txn.Line, Error: fmt.Sprintf("no category found for payee %q", name)})
+				continue
+			}
+			category = fallback
+		}
+
+		externalID := txn.ExternalID
+		result.Rows = append(result.Rows, ImportRow{
+			Line:         txn.Line,
+			CategoryName: category.Name,
+			Expense: expense.CreateExpenseInput{
+				Name:        name,
+				CategoryID:  category.ID,
+				Unit:        1,
+				PerUnitCost: txn.Amount.Abs(),
+				ExpenseDate: txn.Date,
+				Currency:    txn.Currency,
+				ExternalID:  &externalID,
+				Merchant:    txn.Payee,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// loadPayeeCategories returns the category most often used for each expense
+// name the user has entered before, keyed by lower-cased name
+func (s *Service) loadPayeeCategories(userID uint) (map[string]uint, error) {
+	var rows []struct {
+		Name       string
+		CategoryID uint
+		Uses       int64
+	}
+	err := s.db.Model(&models.Expense{}).
+		Select("LOWER(name) as name, category_id, COUNT(*) as uses").
+		Where("user_id = ?", userID).
+		Group("LOWER(name), category_id").
+		Order("uses DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	payees := make(map[string]uint, len(rows))
+	for _, row := range rows {
+		if _, ok := payees[row.Name]; !ok {
+			payees[row.Name] = row.CategoryID
+		}
+	}
+	return payees, nil
+}
@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+func TestParseOFX(t *testing.T) {
+	sgml := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>eur
+<BANKACCTFROM><ACCTID>DE123</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260305120000.000[-5:EST]
+<TRNAMT>-12,50
+<FITID>A1
+<NAME>Caf&eacute; &amp; Bar
+<MEMO>card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260306
+<TRNAMT>1000.00
+<FITID>A2
+<PAYEE>Employer
+</STMTTRN>
+<STMTTRN>
+<DTPOSTED>20260307
+<TRNAMT>-5.00
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+	xml := `<?xml version="1.0"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD</CURDEF>
+<BANKACCTFROM><ACCTID>99</ACCTID></BANKACCTFROM>
+<BANKTRANLIST><STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260101</DTPOSTED><TRNAMT>-3.99</TRNAMT><FITID>X</FITID><NAME>Shop</NAME></STMTTRN></BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+	t.Run("sgml", func(t *testing.T) {
+		transactions, lineErrors, err := ParseOFX(strings.NewReader(sgml))
+		if err != nil {
+			t.Fatalf("ParseOFX() error = %v", err)
+		}
+		if len(transactions) != 2 {
+			t.Fatalf("got %d transactions, want 2", len(transactions))
+		}
+		first := transactions[0]
+		if first.ExternalID != "ofx:DE123:A1" || first.Amount != -1250 || first.Currency != "EUR" ||
+			first.Payee != "Caf&eacute; & Bar" || first.Memo != "card 1234" || first.Line != 9 ||
+			!first.Date.Equal(time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("first transaction = %+v", first)
+		}
+		if transactions[1].Payee != "Employer" || transactions[1].Amount != 100000 {
+			t.Errorf("second transaction = %+v", transactions[1])
+		}
+		if len(lineErrors) != 1 || lineErrors[0].Error != "transaction has no FITID" || lineErrors[0].Line != 24 {
+			t.Errorf("line errors = %+v", lineErrors)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		transactions, lineErrors, err := ParseOFX(strings.NewReader(xml))
+		if err != nil || len(lineErrors) != 0 {
+			t.Fatalf("ParseOFX() = %v, %v", lineErrors, err)
+		}
+		if len(transactions) != 1 || transactions[0].ExternalID != "ofx:99:X" || transactions[0].Amount != -399 || transactions[0].Payee != "Shop" {
+			t.Errorf("transactions = %+v", transactions)
+		}
+	})
+
+	t.Run("not ofx", func(t *testing.T) {
+		if _, _, err := ParseOFX(strings.NewReader("date,amount\n")); err == nil {
+			t.Error("ParseOFX() should reject non-OFX input")
+		}
+	})
+}
+
+func TestParseQIF(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		dateFormat string
+		want       []money.Amount
+		wantDates  []string
+		wantErrors int
+	}{
+		{
+			name:      "decimal point",
+			file:      "!Type:Bank\nD3/5/2026\nT-1,234.50\nPGrocer\n^\nD3/6'26\nU-7.00\nPBakery\n^\n",
+			want:      []money.Amount{-123450, -700},
+			wantDates: []string{"2026-03-05", "2026-03-06"},
+		},
+		{
+			name:       "decimal comma",
+			file:       "!Type:Bank\nD05.03.2026\nT-12,50\nPBäckerei\n^\nD06.03.2026\nT-1.234,56\nPMiete\n^\n",
+			dateFormat: "DD.MM.YYYY",
+			want:       []money.Amount{-1250, -123456},
+			wantDates:  []string{"2026-03-05", "2026-03-06"},
+		},
+		{
+			name:       "bad record",
+			file:       "D3/5/2026\nTlots\nPGrocer\n^\nDyesterday\nT-1.00\n^\n",
+			wantErrors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, lineErrors, err := ParseQIF(strings.NewReader(tt.file), tt.dateFormat)
+			if err != nil {
+				t.Fatalf("ParseQIF() error = %v", err)
+			}
+			if len(lineErrors) != tt.wantErrors {
+				t.Errorf("line errors = %+v, want %d", lineErrors, tt.wantErrors)
+			}
+			if len(transactions) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d", len(transactions), len(tt.want))
+			}
+			for i, txn := range transactions {
+				if txn.Amount != tt.want[i] || txn.Date.Format("2006-01-02") != tt.wantDates[i] {
+					t.Errorf("transaction %d = %s on %s, want %s on %s", i, txn.Amount, txn.Date.Format("2006-01-02"), tt.want[i], tt.wantDates[i])
+				}
+				if !strings.HasPrefix(txn.ExternalID, "qif:") {
+					t.Errorf("transaction %d external ID = %q", i, txn.ExternalID)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQIFRepeatedTransactions(t *testing.T) {
+	file := "D3/5/2026\nT-2.00\nPCoffee\n^\nD3/5/2026\nT-2.00\nPCoffee\n^\n"
+
+	first, _, err := ParseQIF(strings.NewReader(file), "")
+	if err != nil {
+		t.Fatalf("ParseQIF() error = %v", err)
+	}
+	second, _, _ := ParseQIF(strings.NewReader(file), "")
+
+	if len(first) != 2 || first[0].ExternalID == first[1].ExternalID {
+		t.Fatalf("identical transactions should get distinct IDs: %+v", first)
+	}
+	// Re-importing the same file must produce the same IDs
+	for i := range first {
+		if first[i].ExternalID != second[i].ExternalID {
+			t.Errorf("transaction %d ID changed between imports", i)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+)
+
+// ColumnMapping maps expense fields to CSV columns. Each value is either a
+// header name or a zero-based column index. Category may be given by ID or by
+// name, and the cost by per_unit_cost or by amount (the row total).
+type ColumnMapping struct {
+	Name        string `json:"name"`
+	CategoryID  string `json:"category_id"`
+	Category    string `json:"category"`
+	Unit        string `json:"unit"`
+	PerUnitCost string `json:"per_unit_cost"`
+	Amount      string `json:"amount"`
+	ExpenseDate string `json:"expense_date"`
+	Currency    string `json:"currency"`
+	Merchant    string `json:"merchant"`
+}
+
+// CSVOptions configures how a CSV file is parsed
+type CSVOptions struct {
+	Mapping    ColumnMapping
+	DateFormat string
+	Delimiter  rune
+	HasHeader  bool
+}
+
+// resolvedColumns holds the column index of each mapped field (-1 if unmapped)
+type resolvedColumns struct {
+	name, categoryID, category, unit, perUnitCost, amount, expenseDate, currency, merchant int
+}
+
+// ParseCSV parses a CSV file into expense rows without writing anything.
+// Lines that cannot be converted are reported in ImportResult.Errors.
+func (s *Service) ParseCSV(userID uint, r io.Reader, opts CSVOptions) (*ImportResult, error) {
+	layout, err := ParseDateFormat(opts.DateFormat)
+	if err != nil {
+		return nil, InvalidFileError(err.Error())
+	}
+
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var header []string
+	if opts.HasHeader {
+		header, err = reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, InvalidFileError("CSV file is empty")
+			}
+			return nil, InvalidFileError("invalid CSV header: " + err.Error())
+		}
+	}
+
+	columns, err := resolveMapping(opts.Mapping, header)
+	if err != nil {
+		return nil, InvalidFileError(err.Error())
+	}
+
+	categories, err := s.loadCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: true, Rows: []ImportRow{}, Errors: []LineError{}}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, LineError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		// Skip blank lines
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		row, err := parseRecord(record, columns, layout, categories)
+		if err != nil {
+			result.Errors = append(result.Errors, LineError{Line: line, Error: err.Error()})
+			continue
+		}
+		row.Line = line
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// parseRecord converts a single CSV record into an import row
+func parseRecord(record []string, columns resolvedColumns, layout string, categories *categoryLookup) (ImportRow, error) {
+	var row ImportRow
+
+	field := func(index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	row.Expense.Name = field(columns.name)
+	if row.Expense.Name == "" {
+		return row, errors.New("name is empty")
+	}
+
+	if columns.categoryID >= 0 {
+		id, err := strconv.ParseUint(field(columns.categoryID), 10, 32)
+		if err != nil {
+			return row, fmt.Errorf("invalid category_id %q", field(columns.categoryID))
+		}
+		category, ok := categories.findByID(uint(id))
+		if !ok {
+			return row, fmt.Errorf("category %d not found", id)
+		}
+		row.Expense.CategoryID = category.ID
+		row.CategoryName = category.Name
+	} else {
+		category, ok := categories.findByName(field(columns.category))
+		if !ok {
+			return row, fmt.Errorf("category %q not found", field(columns.category))
+		}
+		row.Expense.CategoryID = category.ID
+		row.CategoryName = category.Name
+	}
+
+	row.Expense.Unit = 1
+	if columns.unit >= 0 && field(columns.unit) != "" {
+		unit, err := parseNumber(field(columns.unit))
+		if err != nil {
+			return row, fmt.Errorf("invalid unit %q", field(columns.unit))
+		}
+		row.Expense.Unit = unit
+	}
+	if row.Expense.Unit <= 0 {
+		return row, errors.New("unit must be positive")
+	}
+
+	if columns.perUnitCost >= 0 {
+		cost, err := parseAmount(field(columns.perUnitCost))
+		if err != nil {
+			return row, fmt.Errorf("invalid per_unit_cost %q", field(columns.perUnitCost))
+		}
+		row.Expense.PerUnitCost = cost
+	} else {
+		amount, err := parseAmount(field(columns.amount))
+		if err != nil {
+			return row, fmt.Errorf("invalid amount %q", field(columns.amount))
+		}
+		// The total is recomputed as per-unit cost × unit when saved, so an
+		// amount that doesn't split into whole cents per unit would change
+		cost := amount.Div(row.Expense.Unit)
+		if cost.Mul(row.Expense.Unit) != amount {
+			return row, fmt.Errorf("amount %s can't be split evenly over unit %s", amount, strconv.FormatFloat(row.Expense.Unit, 'f', -1, 64))
+		}
+		row.Expense.PerUnitCost = cost
+	}
+	if row.Expense.PerUnitCost <= 0 {
+		return row, errors.New("amount must be positive")
+	}
+
+	if value := field(columns.expenseDate); value != "" {
+		date, err := time.Parse(layout, value)
+		if err != nil {
+			return row, fmt.Errorf("invalid date %q (expected format %s)", value, layout)
+		}
+		row.Expense.ExpenseDate = date
+	}
+
+	// Empty currencies default to the user's base currency on commit
+	if value := field(columns.currency); value != "" {
+		code, ok := currency.NormalizeCode(value)
+		if !ok {
+			return row, fmt.Errorf("invalid currency %q", value)
+		}
+		row.Expense.Currency = code
+	}
+
+	row.Expense.Merchant = field(columns.merchant)
+
+	return row, nil
+}
+
+// resolveMapping turns header names or indexes into column indexes
+func resolveMapping(mapping ColumnMapping, header []string) (resolvedColumns, error) {
+	columns := resolvedColumns{}
+
+	resolve := func(field, ref string, required bool) (int, error) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			if required {
+				return -1, fmt.Errorf("mapping for %s is required", field)
+			}
+			return -1, nil
+		}
+		if index, err := strconv.Atoi(ref); err == nil && index >= 0 {
+			return index, nil
+		}
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), ref) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("column %q for %s not found", ref, field)
+	}
+
+	var err error
+	if columns.name, err = resolve("name", mapping.Name, true); err != nil {
+		return columns, err
+	}
+	if columns.categoryID, err = resolve("category_id", mapping.CategoryID, false); err != nil {
+		return columns, err
+	}
+	if columns.category, err = resolve("category", mapping.Category, false); err != nil {
+		return columns, err
+	}
+	if columns.categoryID < 0 && columns.category < 0 {
+		return columns, errors.New("mapping for category_id or category is required")
+	}
+	if columns.unit, err = resolve("unit", mapping.Unit, false); err != nil {
+		return columns, err
+	}
+	if columns.perUnitCost, err = resolve("per_unit_cost", mapping.PerUnitCost, false); err != nil {
+		return columns, err
+	}
+	if columns.amount, err = resolve("amount", mapping.Amount, false); err != nil {
+		return columns, err
+	}
+	if columns.perUnitCost < 0 && columns.amount < 0 {
+		return columns, errors.New("mapping for per_unit_cost or amount is required")
+	}
+	if columns.expenseDate, err = resolve("expense_date", mapping.ExpenseDate, false); err != nil {
+		return columns, err
+	}
+	if columns.currency, err = resolve("currency", mapping.Currency, false); err != nil {
+		return columns, err
+	}
+	if columns.merchant, err = resolve("merchant", mapping.Merchant, false); err != nil {
+		return columns, err
+	}
+
+	return columns, nil
+}
+
+// ParseDateFormat converts a user supplied date format into a Go time layout.
+// It accepts either tokens such as YYYY-MM-DD or DD/MM/YYYY, or a Go layout.
+func ParseDateFormat(format string) (string, error) {
+	if format == "" {
+		return "2006-01-02", nil
+	}
+
+	layout := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"DD", "02",
+		"HH", "15",
+		"mm", "04",
+		"ss", "05",
+	).Replace(format)
+
+	// Round-trip a known date to make sure the layout is usable
+	sample := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	parsed, err := time.Parse(layout, sample.Format(layout))
+	if err != nil || parsed.Year() != 2006 || parsed.Month() != 1 || parsed.Day() != 2 {
+		return "", fmt.Errorf("invalid date format %q", format)
+	}
+
+	return layout, nil
+}
+
+// ParseDelimiter converts a user supplied delimiter into a rune
+func ParseDelimiter(delimiter string) (rune, error) {
+	switch strings.ToLower(delimiter) {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	runes := []rune(delimiter)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", delimiter)
+	}
+	return runes[0], nil
+}
+
+// parseNumber parses a number written with a decimal point or a decimal
+// comma, ignoring thousands separators and whitespace
+func parseNumber(value string) (float64, error) {
+	return strconv.ParseFloat(normalizeDecimal(value), 64)
+}
+
+// parseAmount parses a money amount exactly. It may be written with a
+// decimal point or a decimal comma, with thousands separators and
+// whitespace.
+func parseAmount(value string) (money.Amount, error) {
+	return money.Parse(normalizeDecimal(value))
+}
+
+// normalizeDecimal rewrites a number to use a decimal point and no
+// thousands separators or whitespace. A comma followed by at most two
+// digits at the end is the decimal separator, and any dots before it group
+// thousands ("-1.234,50", as in many European locales); otherwise commas
+// group thousands ("1,234.50", "1,234").
+func normalizeDecimal(value string) string {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	comma := strings.LastIndex(value, ",")
+	if comma >= 0 && comma > strings.LastIndex(value, ".") && len(value)-comma-1 <= 2 {
+		return strings.ReplaceAll(value[:comma], ".", "") + "." + value[comma+1:]
+	}
+	return strings.ReplaceAll(value, ",", "")
+}
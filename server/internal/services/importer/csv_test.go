@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+func testCategories() *categoryLookup {
+	userID := uint(7)
+	return newCategoryLookup([]models.Category{
+		{ID: 1, Name: "Food & Dining", IsDefault: true},
+		{ID: 2, Name: "Travel", IsDefault: true},
+		{ID: 9, Name: "travel", UserID: &userID},
+	})
+}
+
+func TestParseRecord(t *testing.T) {
+	header := []string{"Date", "Item", "Category", "Qty", "Amount", "Currency"}
+	columns, err := resolveMapping(ColumnMapping{
+		Name:        "Item",
+		Category:    "Category",
+		Unit:        "Qty",
+		Amount:      "Amount",
+		ExpenseDate: "Date",
+		Currency:    "Currency",
+	}, header)
+	if err != nil {
+		t.Fatalf("resolveMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		record       []string
+		wantCategory uint
+		wantUnit     float64
+		wantCost     money.Amount
+		wantCurrency string
+		wantErr      string
+	}{
+		{
+			name:         "amount with unit",
+			record:       []string{"2026-03-01", "Coffee", "food & dining", "2", "7.00", "usd"},
+			wantCategory: 1, wantUnit: 2, wantCost: 350, wantCurrency: "USD",
+		},
+		{
+			name:         "own category wins over default",
+			record:       []string{"2026-03-01", "Train", "Travel", "", "1,234.50", ""},
+			wantCategory: 9, wantUnit: 1, wantCost: 123450,
+		},
+		{
+			name:         "decimal comma",
+			record:       []string{"2026-03-01", "Brot", "Food & Dining", "", "12,50", "EUR"},
+			wantCategory: 1, wantUnit: 1, wantCost: 1250, wantCurrency: "EUR",
+		},
+		{
+			name:         "decimal comma with thousands dots",
+			record:       []string{"2026-03-01", "Miete", "Travel", "", "1.234,56", ""},
+			wantCategory: 9, wantUnit: 1, wantCost: 123456,
+		},
+		{
+			name:         "decimal point with thousands commas",
+			record:       []string{"2026-03-01", "Rent", "Travel", "", "1,234.56", ""},
+			wantCategory: 9, wantUnit: 1, wantCost: 123456,
+		},
+		{
+			name:         "decimal comma unit",
+			record:       []string{"2026-03-01", "Käse", "Food & Dining", "1,5", "3,00", ""},
+			wantCategory: 1, wantUnit: 1.5, wantCost: 200,
+		},
+		{
+			name:    "amount not divisible by unit",
+			record:  []string{"2026-03-01", "Bagels", "Food & Dining", "3", "10.00", ""},
+			wantErr: "amount 10.00 can't be split evenly over unit 3",
+		},
+		{
+			name:    "unknown category",
+			record:  []string{"2026-03-01", "Gift", "Presents", "1", "5", ""},
+			wantErr: `category "Presents" not found`,
+		},
+		{
+			name:    "empty name",
+			record:  []string{"2026-03-01", " ", "Travel", "1", "5", ""},
+			wantErr: "name is empty",
+		},
+		{
+			name:    "negative amount",
+			record:  []string{"2026-03-01", "Refund", "Travel", "1", "-5", ""},
+			wantErr: "amount must be positive",
+		},
+		{
+			name:    "bad date",
+			record:  []string{"01/03/2026", "Taxi", "Travel", "1", "5", ""},
+			wantErr: `invalid date "01/03/2026" (expected format 2006-01-02)`,
+		},
+		{
+			name:    "bad currency",
+			record:  []string{"2026-03-01", "Taxi", "Travel", "1", "5", "dollars"},
+			wantErr: `invalid currency "dollars"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := parseRecord(tt.record, columns, "2006-01-02", testCategories())
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseRecord() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRecord() error = %v", err)
+			}
+			got := row.Expense
+			if got.CategoryID != tt.wantCategory || got.Unit != tt.wantUnit || got.PerUnitCost != tt.wantCost || got.Currency != tt.wantCurrency {
+				t.Errorf("parseRecord() = category %d, unit %v, cost %s, currency %q; want %d, %v, %s, %q",
+					got.CategoryID, got.Unit, got.PerUnitCost, got.Currency, tt.wantCategory, tt.wantUnit, tt.wantCost, tt.wantCurrency)
+			}
+			if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !got.ExpenseDate.Equal(want) {
+				t.Errorf("parseRecord() date = %v, want %v", got.ExpenseDate, want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    money.Amount
+		wantErr bool
+	}{
+		{"-12.50", -1250, false},
+		{"-12,50", -1250, false},
+		{"-12,5", -1250, false},
+		{"1,234.56", 123456, false},
+		{"-1.234,56", -123456, false},
+		{"1 234,56", 123456, false},
+		{"1,234", 123400, false},
+		{"1,234,567.89", 123456789, false},
+		{"12,50", 1250, false},
+		{"1.234,5", 123450, false},
+		{" 7 ", 700, false},
+		{"42", 4200, false},
+		{"0.005", 1, false},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseAmount(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseAmount(%q) = %s, %v; want %s, error %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveMapping(t *testing.T) {
+	header := []string{"Name", " Category ", "Cost"}
+
+	tests := []struct {
+		name    string
+		mapping ColumnMapping
+		wantErr string
+	}{
+		{"by header", ColumnMapping{Name: "name", Category: "category", PerUnitCost: "COST"}, ""},
+		{"by index", ColumnMapping{Name: "0", CategoryID: "1", Amount: "2"}, ""},
+		{"missing name", ColumnMapping{Category: "Category", Amount: "Cost"}, "mapping for name is required"},
+		{"missing category", ColumnMapping{Name: "Name", Amount: "Cost"}, "mapping for category_id or category is required"},
+		{"missing cost", ColumnMapping{Name: "Name", Category: "Category"}, "mapping for per_unit_cost or amount is required"},
+		{"unknown column", ColumnMapping{Name: "Title", Category: "Category", Amount: "Cost"}, `column "Title" for name not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveMapping(tt.mapping, header)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("resolveMapping() error = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDateFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"", "2006-01-02", false},
+		{"DD/MM/YYYY", "02/01/2006", false},
+		{"MM-DD-YY", "01-02-06", false},
+		{"YYYY-MM-DD HH:mm:ss", "2006-01-02 15:04:05", false},
+		{"2006-01-02", "2006-01-02", false},
+		{"YYYY", "", true},
+		{"whenever", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseDateFormat(tt.format)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseDateFormat(%q) = %q, %v; want %q, error %v", tt.format, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		delimiter string
+		want      rune
+		wantErr   bool
+	}{
+		{"", ',', false},
+		{";", ';', false},
+		{"tab", '\t', false},
+		{`\t`, '\t', false},
+		{"|", '|', false},
+		{`"`, 0, true},
+		{";;", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.delimiter, func(t *testing.T) {
+			got, err := ParseDelimiter(tt.delimiter)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseDelimiter(%q) = %q, %v; want %q, error %v", tt.delimiter, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"strings"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+	"gorm.io/gorm"
+)
+
+// Service handles importing expenses from external files
+type Service struct {
+	db             *gorm.DB
+	expenseService *expense.Service
+}
+
+// NewService creates a new importer service instance
+func NewService(db *gorm.DB, expenseService *expense.Service) *Service {
+	return &Service{
+		db:             db,
+		expenseService: expenseService,
+	}
+}
+
+// InvalidFileError is returned when an import file or its options cannot be
+// used at all, as opposed to individual lines failing
+type InvalidFileError string
+
+func (e InvalidFileError) Error() string {
+	return string(e)
+}
+
+// LineError describes why a single line of an import file was rejected
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportRow is a parsed expense together with the line it came from.
+// Duplicates and DuplicateOfLine are set when the row looks like an
+// existing expense or an earlier row of the same file.
+type ImportRow struct {
+	Line            int                        `json:"line"`
+	CategoryName    string                     `json:"category_name"`
+	Expense         expense.CreateExpenseInput `json:"expense"`
+	Duplicates      []models.Expense           `json:"duplicates,omitempty"`
+	DuplicateOfLine int                        `json:"duplicate_of_line,omitempty"`
+}
+
+// ImportResult is returned by both dry runs and committed imports
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Rows     []ImportRow      `json:"rows"`
+	Errors   []LineError      `json:"errors"`
+	Skipped  int              `json:"skipped"`
+	Created  []models.Expense `json:"created,omitempty"`
+	Imported int              `json:"imported"`
+}
+
+// FindDuplicates flags rows that look like duplicates of existing expenses
+// or of earlier rows, and reports whether any were found
+func (s *Service) FindDuplicates(userID uint, result *ImportResult) (bool, error) {
+	inputs := make([]expense.CreateExpenseInput, len(result.Rows))
+	for i, row := range result.Rows {
+		inputs[i] = row.Expense
+	}
+
+	warnings, err := s.expenseService.FindDuplicates(userID, inputs)
+	if err != nil {
+		return false, err
+	}
+	result.MarkDuplicates(warnings)
+	return len(warnings) > 0, nil
+}
+
+// MarkDuplicates copies duplicate warnings of the expense service onto the rows
+func (r *ImportResult) MarkDuplicates(warnings []expense.DuplicateWarning) {
+	for _, warning := range warnings {
+		row := &r.Rows[warning.Index]
+		row.Duplicates = warning.Duplicates
+		if warning.DuplicateOfIndex != nil {
+			row.DuplicateOfLine = r.Rows[*warning.DuplicateOfIndex].Line
+		}
+	}
+}
+
+// Commit creates the parsed rows through the expense service in one
+// transaction and flags the rows that look like duplicates
+func (s *Service) Commit(userID uint, result *ImportResult) error {
+	inputs := make([]expense.CreateExpenseInput, len(result.Rows))
+	for i, row := range result.Rows {
+		inputs[i] = row.Expense
+	}
+
+	created, warnings, err := s.expenseService.CreateBulk(userID, inputs)
+	if err != nil {
+		return err
+	}
+	result.MarkDuplicates(warnings)
+
+	result.Created = created
+	result.Imported = len(created)
+	return nil
+}
+
+// categoryLookup resolves category names visible to the user (default +
+// user-specific), case-insensitively
+type categoryLookup struct {
+	byName map[string]models.Category
+	byID   map[uint]models.Category
+}
+
+func (s *Service) loadCategories(userID uint) (*categoryLookup, error) {
+	var categories []models.Category
+	if err := s.db.Where("user_id IS NULL OR user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return newCategoryLookup(categories), nil
+}
+
+func newCategoryLookup(categories []models.Category) *categoryLookup {
+	lookup := &categoryLookup{
+		byName: make(map[string]models.Category, len(categories)),
+		byID:   make(map[uint]models.Category, len(categories)),
+	}
+	for _, category := range categories {
+		key := strings.ToLower(strings.TrimSpace(category.Name))
+		// Prefer the user's own category over a default with the same name
+		if existing, ok := lookup.byName[key]; !ok || existing.UserID == nil {
+			lookup.byName[key] = category
+		}
+		lookup.byID[category.ID] = category
+	}
+
+	return lookup
+}
+
+func (l *categoryLookup) findByName(name string) (models.Category, bool) {
+	category, ok := l.byName[strings.ToLower(strings.TrimSpace(name))]
+	return category, ok
+}
+
+func (l *categoryLookup) findByID(id uint) (models.Category, bool) {
+	category, ok := l.byID[id]
+	return category, ok
+}
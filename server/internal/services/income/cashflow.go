@@ -0,0 +1,211 @@
+package income
+
+import (
+	"math"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"github.com/parvejmia9/minflow/server/internal/services/expense"
+)
+
+// Cash-flow intervals
+const (
+	IntervalDay   = "day"
+	IntervalMonth = "month"
+)
+
+// Longest ranges a cash flow can cover, so the periods stay bounded
+const (
+	MaxCashFlowDays   = 366
+	MaxCashFlowMonths = 120
+)
+
+// convertedIncome converts incomes.amount into the base currency (bound to
+// the three ? placeholders), see currency.ConvertedSQL
+var convertedIncome = currency.ConvertedSQL("incomes.amount", "incomes.currency", "incomes.date")
+
+// convertedExpense does the same for expenses.total
+var convertedExpense = currency.ConvertedSQL("expenses.total", "expenses.currency", "expenses.expense_date")
+
+// CashFlowQuery represents the query parameters for cash-flow analytics
+type CashFlowQuery struct {
+	UserID    uint
+	StartDate time.Time
+	EndDate   time.Time
+	Interval  string // day or month
+}
+
+// CashFlowResult compares income with expenses over a date range. Amounts
+// are in Currency, the user's base currency; entries without a known rate
+// are left out of the totals and counted in UnconvertedCount.
+type CashFlowResult struct {
+	Currency         string            `json:"currency"`
+	Interval         string            `json:"interval"`
+	UnconvertedCount int64             `json:"unconverted_count"`
+	Income           money.Amount      `json:"income"`
+	Expenses         money.Amount      `json:"expenses"`
+	NetCashFlow      money.Amount      `json:"net_cash_flow"`
+	SavingsRate      *float64          `json:"savings_rate"` // percent of income not spent, nil without income
+	Periods          []CashFlowPeriod  `json:"periods"`
+	DateRange        expense.DateRange `json:"date_range"`
+}
+
+// CashFlowPeriod is the cash flow of one day (YYYY-MM-DD) or month (YYYY-MM)
+type CashFlowPeriod struct {
+	Period      string       `json:"period"`
+	Income      money.Amount `json:"income"`
+	Expenses    money.Amount `json:"expenses"`
+	NetCashFlow money.Amount `json:"net_cash_flow"`
+	SavingsRate *float64     `json:"savings_rate"`
+}
+
+// ValidInterval reports whether the cash-flow interval is supported
+func ValidInterval(interval string) bool {
+	return interval == IntervalDay || interval == IntervalMonth
+}
+
+// PeriodCount returns how many days or months the range covers, counting the
+// first and last one
+func PeriodCount(start, end time.Time, interval string) int {
+	if interval == IntervalMonth {
+		return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// GetCashFlow returns income, expenses, net cash flow and savings rate for
+// the range and for every day or month in it
+func (s *Service) GetCashFlow(query CashFlowQuery) (*CashFlowResult, error) {
+	if !ValidInterval(query.Interval) {
+		query.Interval = IntervalDay
+	}
+
+	baseCurrency, err := s.baseCurrency(query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	rateArgs := []interface{}{baseCurrency, baseCurrency, baseCurrency}
+
+	format, layout := "YYYY-MM-DD", "2006-01-02"
+	if query.Interval == IntervalMonth {
+		format, layout = "YYYY-MM", "2006-01"
+	}
+
+	type periodTotal struct {
+		Period      string
+		Total       money.Amount
+		Unconverted int64
+	}
+
+	// Expenses use the same filter as expense analytics
+	var expenseTotals []periodTotal
+	filter := expense.ExpenseFilter{
+		UserID:    query.UserID,
+		StartDate: &query.StartDate,
+		EndDate:   &query.EndDate,
+	}
+	err = s.db.Model(&models.Expense{}).
+		Select("TO_CHAR(expenses.expense_date, ?) as period, COALESCE(SUM("+convertedExpense+"), 0) as total, COUNT(*) - COUNT("+convertedExpense+") as unconverted", append(append([]interface{}{format}, rateArgs...), rateArgs...)...).
+		Scopes(filter.Scope).
+		Group("period").
+		Scan(&expenseTotals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var incomeTotals []periodTotal
+	err = s.db.Model(&models.Income{}).
+		Select("TO_CHAR(incomes.date, ?) as period, COALESCE(SUM("+convertedIncome+"), 0) as total, COUNT(*) - COUNT("+convertedIncome+") as unconverted", append(append([]interface{}{format}, rateArgs...), rateArgs...)...).
+		Where("incomes.user_id = ? AND incomes.date >= ? AND incomes.date <= ?", query.UserID, query.StartDate, query.EndDate).
+		Group("period").
+		Scan(&incomeTotals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CashFlowResult{
+		Currency: baseCurrency,
+		Interval: query.Interval,
+		Periods:  []CashFlowPeriod{},
+		DateRange: expense.DateRange{
+			Start: query.StartDate,
+			End:   query.EndDate,
+		},
+	}
+
+	// One entry per day or month of the range, including empty ones
+	index := make(map[string]int)
+	start := query.StartDate
+	if query.Interval == IntervalMonth {
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	}
+	for t := start; !t.After(query.EndDate); {
+		period := t.Format(layout)
+		index[period] = len(result.Periods)
+		result.Periods = append(result.Periods, CashFlowPeriod{Period: period})
+		if query.Interval == IntervalMonth {
+			t = t.AddDate(0, 1, 0)
+		} else {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+
+	for _, row := range expenseTotals {
+		if i, ok := index[row.Period]; ok {
+			result.Periods[i].Expenses = row.Total
+		}
+		result.Expenses += row.Total
+		result.UnconvertedCount += row.Unconverted
+	}
+	for _, row := range incomeTotals {
+		if i, ok := index[row.Period]; ok {
+			result.Periods[i].Income = row.Total
+		}
+		result.Income += row.Total
+		result.UnconvertedCount += row.Unconverted
+	}
+
+	for i := range result.Periods {
+		period := &result.Periods[i]
+		period.NetCashFlow = period.Income - period.Expenses
+		period.SavingsRate = savingsRate(period.Income, period.NetCashFlow)
+	}
+	result.NetCashFlow = result.Income - result.Expenses
+	result.SavingsRate = savingsRate(result.Income, result.NetCashFlow)
+
+	return result, nil
+}
+
+// GetTotal returns the user's income between start and end (inclusive) in
+// their base currency. Incomes without a known rate are left out.
+func (s *Service) GetTotal(userID uint, start, end time.Time) (money.Amount, error) {
+	baseCurrency, err := s.baseCurrency(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total money.Amount
+	err = s.db.Model(&models.Income{}).
+		Select("COALESCE(SUM("+convertedIncome+"), 0)", baseCurrency, baseCurrency, baseCurrency).
+		Where("incomes.user_id = ? AND incomes.date >= ? AND incomes.date <= ?", userID, start, end).
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// savingsRate returns net as a percentage of income, rounded to two
+// decimals, or nil when there was no income
+func savingsRate(income, net money.Amount) *float64 {
+	if income <= 0 {
+		return nil
+	}
+	rate := math.Round(float64(net)/float64(income)*10000) / 100
+	return &rate
+}
@@ -0,0 +1,35 @@
+package income
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodCount(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		interval   string
+		want       int
+	}{
+		{"same day", day(2026, 3, 10), day(2026, 3, 10), IntervalDay, 1},
+		{"end of day", day(2026, 3, 10), day(2026, 3, 11).Add(-time.Second), IntervalDay, 1},
+		{"leap year", day(2028, 1, 1), day(2028, 12, 31), IntervalDay, 366},
+		{"over a year", day(2026, 1, 1), day(2027, 1, 2), IntervalDay, 367},
+		{"same month", day(2026, 3, 1), day(2026, 3, 31), IntervalMonth, 1},
+		{"across a year", day(2025, 11, 30), day(2026, 2, 1), IntervalMonth, 4},
+		{"ten years", day(2016, 1, 1), day(2025, 12, 31), IntervalMonth, 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeriodCount(tt.start, tt.end, tt.interval); got != tt.want {
+				t.Errorf("PeriodCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
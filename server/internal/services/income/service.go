@@ -0,0 +1,236 @@
+package income
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/account"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"gorm.io/gorm"
+)
+
+// Service handles income and cash-flow business logic
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new income service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// CreateIncomeInput represents the input for recording an income
+type CreateIncomeInput struct {
+	Source    string       `json:"source" validate:"required"`
+	Amount    money.Amount `json:"amount" validate:"required,gt=0"`
+	Currency  string       `json:"currency"` // defaults to the user's base currency
+	Date      time.Time    `json:"date"`
+	Recurring bool         `json:"recurring"`
+	AccountID *uint        `json:"account_id,omitempty"` // account paid into, must use the income currency
+	Note      string       `json:"note"`
+}
+
+// UpdateIncomeInput represents the input for partially updating an income.
+// Nil fields are left unchanged.
+type UpdateIncomeInput struct {
+	Source    *string       `json:"source"`
+	Amount    *money.Amount `json:"amount"`
+	Currency  *string       `json:"currency"`
+	Date      *time.Time    `json:"date"`
+	Recurring *bool         `json:"recurring"`
+	AccountID *uint         `json:"account_id"` // 0 removes the account
+	Note      *string       `json:"note"`
+}
+
+// IncomeFilter narrows down a user's incomes. Zero values match everything.
+type IncomeFilter struct {
+	UserID    uint
+	StartDate *time.Time
+	EndDate   *time.Time
+	Recurring *bool
+}
+
+// GetByUser retrieves the incomes matching the filter, newest first
+func (s *Service) GetByUser(filter IncomeFilter) ([]models.Income, error) {
+	var incomes []models.Income
+
+	query := s.db.Preload("Account").Where("user_id = ?", filter.UserID)
+	if filter.StartDate != nil {
+		query = query.Where("date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("date <= ?", *filter.EndDate)
+	}
+	if filter.Recurring != nil {
+		query = query.Where("recurring = ?", *filter.Recurring)
+	}
+
+	if err := query.Order("date DESC, id DESC").Find(&incomes).Error; err != nil {
+		return nil, err
+	}
+
+	return incomes, nil
+}
+
+// GetByID retrieves one of the user's incomes
+func (s *Service) GetByID(id, userID uint) (*models.Income, error) {
+	var income models.Income
+	err := s.db.Preload("Account").Where("id = ? AND user_id = ?", id, userID).First(&income).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("income not found")
+		}
+		return nil, err
+	}
+	return &income, nil
+}
+
+// Create records a new income
+func (s *Service) Create(userID uint, input CreateIncomeInput) (*models.Income, error) {
+	source := strings.TrimSpace(input.Source)
+	if source == "" {
+		return nil, errors.New("source is required")
+	}
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	currencyCode, err := s.resolveCurrency(userID, input.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AccountID != nil && *input.AccountID == 0 {
+		input.AccountID = nil
+	}
+	if input.AccountID != nil {
+		if err := account.Check(s.db, userID, *input.AccountID, currencyCode); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Date.IsZero() {
+		input.Date = time.Now()
+	}
+
+	income := &models.Income{
+		UserID:    userID,
+		Source:    source,
+		Amount:    input.Amount,
+		Currency:  currencyCode,
+		Date:      input.Date,
+		Recurring: input.Recurring,
+		AccountID: input.AccountID,
+		Note:      input.Note,
+	}
+	if err := s.db.Create(income).Error; err != nil {
+		return nil, err
+	}
+
+	// Load account relationship
+	s.db.Preload("Account").First(income, income.ID)
+
+	return income, nil
+}
+
+// Update applies a partial update to one of the user's incomes
+func (s *Service) Update(id, userID uint, input UpdateIncomeInput) (*models.Income, error) {
+	income, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Source != nil {
+		source := strings.TrimSpace(*input.Source)
+		if source == "" {
+			return nil, errors.New("source is required")
+		}
+		income.Source = source
+	}
+	if input.Amount != nil {
+		if *input.Amount <= 0 {
+			return nil, errors.New("amount must be positive")
+		}
+		income.Amount = *input.Amount
+	}
+	if input.Currency != nil {
+		code, ok := currency.NormalizeCode(*input.Currency)
+		if !ok {
+			return nil, errors.New("invalid currency")
+		}
+		income.Currency = code
+	}
+	if input.Date != nil {
+		income.Date = *input.Date
+	}
+	if input.Recurring != nil {
+		income.Recurring = *input.Recurring
+	}
+	if input.Note != nil {
+		income.Note = *input.Note
+	}
+	if input.AccountID != nil {
+		if *input.AccountID == 0 {
+			income.AccountID = nil
+		} else {
+			income.AccountID = input.AccountID
+		}
+	}
+	// Recheck the account when it or the currency changes
+	if income.AccountID != nil && (input.AccountID != nil || input.Currency != nil) {
+		if err := account.Check(s.db, userID, *income.AccountID, income.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	income.Account = nil
+	if err := s.db.Save(income).Error; err != nil {
+		return nil, err
+	}
+
+	// Load account relationship
+	s.db.Preload("Account").First(income, income.ID)
+
+	return income, nil
+}
+
+// Delete deletes one of the user's incomes
+func (s *Service) Delete(id, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Income{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("income not found")
+	}
+	return nil
+}
+
+// baseCurrency returns the currency the user's cash flow is reported in
+func (s *Service) baseCurrency(userID uint) (string, error) {
+	var user models.User
+	if err := s.db.Select("base_currency").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	if user.BaseCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return user.BaseCurrency, nil
+}
+
+// resolveCurrency validates a currency code, defaulting to the user's base currency
+func (s *Service) resolveCurrency(userID uint, code string) (string, error) {
+	if code == "" {
+		return s.baseCurrency(userID)
+	}
+	code, ok := currency.NormalizeCode(code)
+	if !ok {
+		return "", errors.New("invalid currency")
+	}
+	return code, nil
+}
@@ -0,0 +1,472 @@
+package merchant
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxNameLength is the longest allowed merchant name
+const MaxNameLength = 100
+
+// MaxRawLength is the longest raw merchant string that is kept
+const MaxRawLength = 255
+
+// processorPrefixes are payment processors that put their own prefix in
+// front of the merchant, as in "SQ *BLUE BOTTLE"
+var processorPrefixes = map[string]bool{
+	"sq": true, "tst": true, "paypal": true, "pp": true, "sp": true, "iz": true, "sumup": true,
+}
+
+// Service handles merchant business logic
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new merchant service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// MerchantInput represents the input for creating or renaming a merchant
+type MerchantInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// AliasInput represents the input for creating or updating an alias rule
+type AliasInput struct {
+	MerchantID uint   `json:"merchant_id" validate:"required"`
+	Pattern    string `json:"pattern" validate:"required"`
+	MatchType  string `json:"match_type"` // defaults to contains
+}
+
+// MerchantSummary is a merchant with the number of expenses linked to it
+type MerchantSummary struct {
+	models.Merchant
+	ExpenseCount int64 `json:"expense_count"`
+}
+
+// NormalizeResult shows how a raw merchant string would be normalized
+type NormalizeResult struct {
+	Raw        string `json:"raw"`
+	Name       string `json:"name"`
+	MerchantID *uint  `json:"merchant_id"` // nil when the merchant doesn't exist yet
+	AliasID    *uint  `json:"alias_id"`    // the rule that matched, if any
+}
+
+// NormalizeName trims and collapses whitespace in a merchant name and
+// reports whether the result is a valid name
+func NormalizeName(name string) (string, bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	return name, name != "" && len([]rune(name)) <= MaxNameLength
+}
+
+// CleanName turns a raw merchant string without a matching alias into a
+// readable name: processor prefixes, reference codes after "*" or "#" and
+// trailing store numbers are dropped, and all-caps names are title-cased.
+// "SQ *BLUE BOTTLE 12" becomes "Blue Bottle".
+func CleanName(raw string) string {
+	name := strings.TrimSpace(raw)
+
+	if before, after, found := strings.Cut(name, "*"); found {
+		if processorPrefixes[strings.ToLower(strings.TrimSpace(before))] {
+			name = after
+			// The reference code may follow the merchant, as in "SQ *SHOP*123"
+			name, _, _ = strings.Cut(name, "*")
+		} else {
+			name = before
+		}
+	}
+	name, _, _ = strings.Cut(name, "#")
+
+	words := strings.Fields(name)
+	for len(words) > 1 && strings.ContainsFunc(words[len(words)-1], unicode.IsDigit) {
+		words = words[:len(words)-1]
+	}
+	name = strings.Join(words, " ")
+
+	if !strings.ContainsFunc(name, unicode.IsLower) {
+		for i, word := range words {
+			runes := []rune(strings.ToLower(word))
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		name = strings.Join(words, " ")
+	}
+
+	if runes := []rune(name); len(runes) > MaxNameLength {
+		name = strings.TrimSpace(string(runes[:MaxNameLength]))
+	}
+	return name
+}
+
+// Resolver maps raw merchant strings to merchants using a user's alias
+// rules, creating merchants that don't exist yet. It caches the rules, so
+// create one per request or transaction.
+type Resolver struct {
+	db      *gorm.DB
+	userID  uint
+	aliases []models.MerchantAlias
+	regexps map[uint]*regexp.Regexp
+}
+
+// NewResolver loads the user's alias rules. Pass a transaction to make the
+// merchants it creates part of a larger write.
+func NewResolver(db *gorm.DB, userID uint) (*Resolver, error) {
+	var aliases []models.MerchantAlias
+	if err := db.Preload("Merchant").Where("user_id = ?", userID).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	sortAliases(aliases)
+
+	regexps := make(map[uint]*regexp.Regexp)
+	for _, alias := range aliases {
+		if alias.MatchType == models.AliasRegex {
+			// Patterns are validated when saved; skip any that no longer compile
+			if re, err := regexp.Compile("(?i)" + alias.Pattern); err == nil {
+				regexps[alias.ID] = re
+			}
+		}
+	}
+
+	return &Resolver{db: db, userID: userID, aliases: aliases, regexps: regexps}, nil
+}
+
+// Resolve returns the merchant for a raw merchant string, or nil when the
+// string is blank
+func (r *Resolver) Resolve(raw string) (*models.Merchant, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if alias := r.match(raw); alias != nil {
+		merchant := alias.Merchant
+		return &merchant, nil
+	}
+
+	name, ok := NormalizeName(CleanName(raw))
+	if !ok {
+		return nil, nil
+	}
+	return findOrCreate(r.db, r.userID, name)
+}
+
+// match returns the first alias rule matching the raw string
+func (r *Resolver) match(raw string) *models.MerchantAlias {
+	value := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+	for i, alias := range r.aliases {
+		pattern := strings.ToLower(alias.Pattern)
+		matched := false
+		switch alias.MatchType {
+		case models.AliasExact:
+			matched = value == pattern
+		case models.AliasPrefix:
+			matched = strings.HasPrefix(value, pattern)
+		case models.AliasContains:
+			matched = strings.Contains(value, pattern)
+		case models.AliasRegex:
+			if re, ok := r.regexps[alias.ID]; ok {
+				matched = re.MatchString(raw)
+			}
+		}
+		if matched {
+			return &r.aliases[i]
+		}
+	}
+	return nil
+}
+
+// sortAliases orders rules by match type, then the longest (most specific)
+// pattern first
+func sortAliases(aliases []models.MerchantAlias) {
+	rank := map[string]int{
+		models.AliasExact:    0,
+		models.AliasPrefix:   1,
+		models.AliasContains: 2,
+		models.AliasRegex:    3,
+	}
+	sort.SliceStable(aliases, func(i, j int) bool {
+		a, b := aliases[i], aliases[j]
+		if rank[a.MatchType] != rank[b.MatchType] {
+			return rank[a.MatchType] < rank[b.MatchType]
+		}
+		if len(a.Pattern) != len(b.Pattern) {
+			return len(a.Pattern) > len(b.Pattern)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// findOrCreate returns the user's merchant with the given name (compared
+// case-insensitively), creating it if it doesn't exist
+func findOrCreate(db *gorm.DB, userID uint, name string) (*models.Merchant, error) {
+	var merchant models.Merchant
+	err := db.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&merchant).Error
+	if err == nil {
+		return &merchant, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	merchant = models.Merchant{Name: name, UserID: userID}
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoNothing: true,
+	}).Create(&merchant).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Created concurrently by another request
+	if merchant.ID == 0 {
+		if err := db.Where("user_id = ? AND name = ?", userID, name).First(&merchant).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &merchant, nil
+}
+
+// TruncateRaw shortens a raw merchant string to MaxRawLength characters
+func TruncateRaw(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if runes := []rune(raw); len(runes) > MaxRawLength {
+		raw = string(runes[:MaxRawLength])
+	}
+	return raw
+}
+
+// GetByUser retrieves all merchants of a user with their usage counts
+func (s *Service) GetByUser(userID uint) ([]MerchantSummary, error) {
+	var merchants []MerchantSummary
+
+	err := s.db.Model(&models.Merchant{}).
+		Select("merchants.*, COUNT(expenses.id) as expense_count").
+		Joins("LEFT JOIN expenses ON expenses.merchant_id = merchants.id AND expenses.deleted_at IS NULL").
+		Where("merchants.user_id = ?", userID).
+		Group("merchants.id").
+		Order("merchants.name ASC").
+		Scan(&merchants).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return merchants, nil
+}
+
+// Normalize shows which merchant a raw string would be linked to, without
+// creating anything
+func (s *Service) Normalize(userID uint, raw string) (*NormalizeResult, error) {
+	resolver, err := NewResolver(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NormalizeResult{Raw: strings.TrimSpace(raw)}
+	if alias := resolver.match(result.Raw); alias != nil {
+		result.Name = alias.Merchant.Name
+		result.MerchantID = &alias.MerchantID
+		result.AliasID = &alias.ID
+		return result, nil
+	}
+
+	name, ok := NormalizeName(CleanName(result.Raw))
+	if !ok {
+		return nil, errors.New("invalid merchant name")
+	}
+	result.Name = name
+
+	var existing models.Merchant
+	err = s.db.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&existing).Error
+	if err == nil {
+		result.Name = existing.Name
+		result.MerchantID = &existing.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Create creates a new merchant
+func (s *Service) Create(userID uint, input MerchantInput) (*models.Merchant, error) {
+	name, ok := NormalizeName(input.Name)
+	if !ok {
+		return nil, errors.New("invalid merchant name")
+	}
+
+	var count int64
+	s.db.Model(&models.Merchant{}).Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("merchant already exists")
+	}
+
+	merchant := &models.Merchant{Name: name, UserID: userID}
+	if err := s.db.Create(merchant).Error; err != nil {
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// Update renames a merchant. Linked expenses and alias rules keep pointing to it.
+func (s *Service) Update(id, userID uint, input MerchantInput) (*models.Merchant, error) {
+	name, ok := NormalizeName(input.Name)
+	if !ok {
+		return nil, errors.New("invalid merchant name")
+	}
+
+	merchant, err := s.find(s.db, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	s.db.Model(&models.Merchant{}).Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, id).Count(&count)
+	if count > 0 {
+		return nil, errors.New("merchant already exists")
+	}
+
+	merchant.Name = name
+	if err := s.db.Save(merchant).Error; err != nil {
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// Delete deletes a merchant with its alias rules and unlinks its expenses
+func (s *Service) Delete(id, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		merchant, err := s.find(tx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		// Expenses in the trash are unlinked too
+		err = tx.Unscoped().Model(&models.Expense{}).
+			Where("merchant_id = ?", merchant.ID).
+			UpdateColumn("merchant_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("merchant_id = ?", merchant.ID).Delete(&models.MerchantAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(merchant).Error
+	})
+}
+
+// GetAliases retrieves all alias rules of a user in the order they are tried
+func (s *Service) GetAliases(userID uint) ([]models.MerchantAlias, error) {
+	var aliases []models.MerchantAlias
+
+	err := s.db.Preload("Merchant").Where("user_id = ?", userID).Find(&aliases).Error
+	if err != nil {
+		return nil, err
+	}
+	sortAliases(aliases)
+
+	return aliases, nil
+}
+
+// CreateAlias creates a new alias rule
+func (s *Service) CreateAlias(userID uint, input AliasInput) (*models.MerchantAlias, error) {
+	alias := &models.MerchantAlias{UserID: userID}
+	if err := s.applyAliasInput(alias, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(alias).Error; err != nil {
+		return nil, err
+	}
+
+	return alias, nil
+}
+
+// UpdateAlias replaces the pattern, match type and merchant of an alias rule
+func (s *Service) UpdateAlias(id, userID uint, input AliasInput) (*models.MerchantAlias, error) {
+	var alias models.MerchantAlias
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&alias).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alias not found")
+		}
+		return nil, err
+	}
+
+	if err := s.applyAliasInput(&alias, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Omit(clause.Associations).Save(&alias).Error; err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+// DeleteAlias deletes an alias rule. Expenses it was applied to keep their merchant.
+func (s *Service) DeleteAlias(id, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.MerchantAlias{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("alias not found")
+	}
+	return nil
+}
+
+// applyAliasInput validates the input and copies it onto the alias
+func (s *Service) applyAliasInput(alias *models.MerchantAlias, input AliasInput) error {
+	pattern := strings.Join(strings.Fields(input.Pattern), " ")
+	if pattern == "" || len(pattern) > MaxRawLength {
+		return errors.New("invalid pattern")
+	}
+
+	matchType := strings.ToLower(strings.TrimSpace(input.MatchType))
+	switch matchType {
+	case "":
+		matchType = models.AliasContains
+	case models.AliasExact, models.AliasPrefix, models.AliasContains:
+	case models.AliasRegex:
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return errors.New("invalid pattern")
+		}
+	default:
+		return errors.New("invalid match type")
+	}
+
+	merchant, err := s.find(s.db, input.MerchantID, alias.UserID)
+	if err != nil {
+		return err
+	}
+
+	alias.Pattern = pattern
+	alias.MatchType = matchType
+	alias.MerchantID = merchant.ID
+	alias.Merchant = *merchant
+	return nil
+}
+
+// find loads one of the user's merchants
+func (s *Service) find(db *gorm.DB, id, userID uint) (*models.Merchant, error) {
+	var merchant models.Merchant
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("merchant not found")
+		}
+		return nil, err
+	}
+	return &merchant, nil
+}
@@ -0,0 +1,242 @@
+package receipt
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/storage"
+	"gorm.io/gorm"
+)
+
+// DefaultMaxSize is the default upload limit (10 MB)
+const DefaultMaxSize int64 = 10 << 20
+
+// allowedTypes maps the accepted content types to the extension used in storage keys
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// Service handles receipt attachments
+type Service struct {
+	db      *gorm.DB
+	storage storage.Storage
+	maxSize int64
+}
+
+// NewService creates a new receipt service instance. maxSize limits the
+// size of a single upload in bytes (DefaultMaxSize when zero).
+func NewService(db *gorm.DB, store storage.Storage, maxSize int64) *Service {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Service{
+		db:      db,
+		storage: store,
+		maxSize: maxSize,
+	}
+}
+
+// MaxSize returns the upload limit in bytes
+func (s *Service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload validates a file and attaches it to an expense. The content type is
+// detected from the file contents; the client supplied type is not trusted.
+func (s *Service) Upload(ctx context.Context, userID, expenseID uint, fileName string, r io.Reader, size int64) (*models.Receipt, error) {
+	if err := s.verifyExpense(userID, expenseID); err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, errors.New("file is empty")
+	}
+	if size > s.maxSize {
+		return nil, errors.New("file is too large")
+	}
+
+	// Sniff the content type from the first 512 bytes
+	reader := bufio.NewReaderSize(r, 512)
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	extension, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, errors.New("unsupported file type")
+	}
+
+	key, err := storageKey(userID, expenseID, extension)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, io.LimitReader(reader, size), size, contentType); err != nil {
+		return nil, err
+	}
+
+	receipt := &models.Receipt{
+		ExpenseID:   expenseID,
+		UserID:      userID,
+		FileName:    cleanFileName(fileName, extension),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(receipt).Error; err != nil {
+			return err
+		}
+		return touchExpense(tx, expenseID)
+	})
+	if err != nil {
+		// Don't leave an orphaned file behind
+		if deleteErr := s.storage.Delete(ctx, key); deleteErr != nil {
+			log.Printf("receipts: failed to remove %s: %v", key, deleteErr)
+		}
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// GetByExpense lists the receipts attached to an expense
+func (s *Service) GetByExpense(userID, expenseID uint) ([]models.Receipt, error) {
+	if err := s.verifyExpense(userID, expenseID); err != nil {
+		return nil, err
+	}
+
+	var receipts []models.Receipt
+	err := s.db.
+		Where("expense_id = ? AND user_id = ?", expenseID, userID).
+		Order("created_at ASC").
+		Find(&receipts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// Open returns a receipt and a reader for its contents. The caller must
+// close the reader.
+func (s *Service) Open(ctx context.Context, userID, expenseID, receiptID uint) (*models.Receipt, io.ReadCloser, error) {
+	receipt, err := s.getByID(userID, expenseID, receiptID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, receipt.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("receipt file not found")
+		}
+		return nil, nil, err
+	}
+
+	return receipt, content, nil
+}
+
+// Delete removes a receipt and its file
+func (s *Service) Delete(ctx context.Context, userID, expenseID, receiptID uint) error {
+	receipt, err := s.getByID(userID, expenseID, receiptID)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(receipt).Error; err != nil {
+			return err
+		}
+		return touchExpense(tx, expenseID)
+	})
+	if err != nil {
+		return err
+	}
+	return s.storage.Delete(ctx, receipt.StorageKey)
+}
+
+// DeleteFiles removes the stored files of receipts whose rows were already
+// deleted, e.g. when their expense is purged. Files that can't be removed
+// are logged and skipped.
+func (s *Service) DeleteFiles(ctx context.Context, receipts []models.Receipt) {
+	for _, receipt := range receipts {
+		if err := s.storage.Delete(ctx, receipt.StorageKey); err != nil {
+			log.Printf("receipts: failed to remove %s: %v", receipt.StorageKey, err)
+		}
+	}
+}
+
+// getByID retrieves a receipt of one of the user's expenses
+func (s *Service) getByID(userID, expenseID, receiptID uint) (*models.Receipt, error) {
+	var receipt models.Receipt
+	err := s.db.
+		Where("id = ? AND expense_id = ? AND user_id = ?", receiptID, expenseID, userID).
+		First(&receipt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("receipt not found")
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// touchExpense bumps the updated_at of an expense whose receipts changed, so
+// its ETag changes with them
+func touchExpense(tx *gorm.DB, expenseID uint) error {
+	return tx.Model(&models.Expense{}).Where("id = ?", expenseID).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// verifyExpense checks that an expense exists and belongs to the user
+func (s *Service) verifyExpense(userID, expenseID uint) error {
+	var count int64
+	err := s.db.Model(&models.Expense{}).
+		Where("id = ? AND user_id = ?", expenseID, userID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("expense not found")
+	}
+	return nil
+}
+
+// storageKey builds a unique key for a new receipt file
+func storageKey(userID, expenseID uint, extension string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("receipts/%d/%d/%s%s", userID, expenseID, hex.EncodeToString(random), extension), nil
+}
+
+// cleanFileName strips directories from a client supplied file name
+func cleanFileName(name, extension string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		return "receipt" + extension
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "")
+	}
+	return name
+}
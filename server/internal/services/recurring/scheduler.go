@@ -0,0 +1,31 @@
+package recurring
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartScheduler posts due recurring expenses immediately and then on every
+// tick of the given interval, until the context is cancelled. It blocks, so
+// run it in its own goroutine.
+func (s *Service) StartScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := s.MaterializeDue(time.Now())
+		if err != nil {
+			log.Println("Warning: Failed to post recurring expenses:", err)
+		}
+		if created > 0 {
+			log.Printf("Posted %d recurring expense(s)", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
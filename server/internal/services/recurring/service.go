@@ -0,0 +1,413 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCatchUp caps how many missed occurrences of one schedule are posted per run
+const maxCatchUp = 1000
+
+// Service handles recurring expense business logic
+type Service struct {
+	db       *gorm.DB
+	onCreate []func(userID uint)
+}
+
+// NewService creates a new recurring expense service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// OnCreate registers fn to be called after occurrences are posted for a user
+func (s *Service) OnCreate(fn func(userID uint)) {
+	s.onCreate = append(s.onCreate, fn)
+}
+
+// CreateInput represents the input for creating a recurring expense
+type CreateInput struct {
+	Name        string       `json:"name" validate:"required"`
+	CategoryID  uint         `json:"category_id" validate:"required"`
+	Unit        float64      `json:"unit" validate:"required,gt=0"`
+	PerUnitCost money.Amount `json:"per_unit_cost" validate:"required,gt=0"`
+	Currency    string       `json:"currency"` // defaults to the user's base currency
+	Frequency   string       `json:"frequency" validate:"required"`
+	Interval    int          `json:"interval"`
+	StartDate   time.Time    `json:"start_date"`
+	EndDate     *time.Time   `json:"end_date"`
+}
+
+// UpdateInput represents the input for partially updating a recurring expense.
+// Nil fields are left unchanged.
+type UpdateInput struct {
+	Name        *string       `json:"name"`
+	CategoryID  *uint         `json:"category_id"`
+	Unit        *float64      `json:"unit"`
+	PerUnitCost *money.Amount `json:"per_unit_cost"`
+	Currency    *string       `json:"currency"`
+	Frequency   *string       `json:"frequency"`
+	Interval    *int          `json:"interval"`
+	StartDate   *time.Time    `json:"start_date"`
+	EndDate     *time.Time    `json:"end_date"`
+	ClearEnd    bool          `json:"clear_end_date"`
+	Active      *bool         `json:"active"`
+}
+
+// ValidFrequency reports whether the frequency is supported
+func ValidFrequency(frequency string) bool {
+	switch frequency {
+	case models.FrequencyDaily, models.FrequencyWeekly, models.FrequencyMonthly, models.FrequencyYearly:
+		return true
+	}
+	return false
+}
+
+// Create creates a new recurring expense
+func (s *Service) Create(userID uint, input CreateInput) (*models.RecurringExpense, error) {
+	if err := s.verifyCategory(userID, input.CategoryID); err != nil {
+		return nil, err
+	}
+
+	currencyCode, err := s.resolveCurrency(userID, input.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start today if no start date is provided
+	if input.StartDate.IsZero() {
+		input.StartDate = time.Now()
+	}
+	if input.Interval == 0 {
+		input.Interval = 1
+	}
+	if input.EndDate != nil && input.EndDate.Before(input.StartDate) {
+		return nil, errors.New("end_date must be after start_date")
+	}
+
+	recurring := &models.RecurringExpense{
+		Name:        input.Name,
+		CategoryID:  input.CategoryID,
+		UserID:      userID,
+		Unit:        input.Unit,
+		PerUnitCost: input.PerUnitCost,
+		Currency:    currencyCode,
+		Frequency:   input.Frequency,
+		Interval:    input.Interval,
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+		Active:      true,
+	}
+	recurring.NextRunDate = recurring.NextOccurrence()
+
+	if err := s.db.Create(recurring).Error; err != nil {
+		return nil, err
+	}
+
+	// Load category relationship
+	s.db.Preload("Category").First(recurring, recurring.ID)
+
+	return recurring, nil
+}
+
+// GetByUser retrieves all recurring expenses for a user
+func (s *Service) GetByUser(userID uint) ([]models.RecurringExpense, error) {
+	var recurring []models.RecurringExpense
+
+	err := s.db.
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&recurring).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recurring, nil
+}
+
+// GetByID retrieves a single recurring expense by ID
+func (s *Service) GetByID(id, userID uint) (*models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+
+	err := s.db.
+		Preload("Category").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&recurring).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurring expense not found")
+		}
+		return nil, err
+	}
+
+	return &recurring, nil
+}
+
+// Update applies a partial update to a recurring expense. When the schedule
+// changes after occurrences were posted, it resumes with the first new
+// occurrence after the last posted one and not before today, so periods
+// that were already covered are never posted again. A paused schedule that
+// is activated again likewise resumes today instead of posting what was
+// missed while it was paused.
+func (s *Service) Update(id, userID uint, input UpdateInput) (*models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&recurring).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurring expense not found")
+		}
+		return nil, err
+	}
+
+	if input.Name != nil {
+		recurring.Name = *input.Name
+	}
+	if input.CategoryID != nil {
+		if err := s.verifyCategory(userID, *input.CategoryID); err != nil {
+			return nil, err
+		}
+		recurring.CategoryID = *input.CategoryID
+	}
+	if input.Unit != nil {
+		recurring.Unit = *input.Unit
+	}
+	if input.PerUnitCost != nil {
+		recurring.PerUnitCost = *input.PerUnitCost
+	}
+	if input.Currency != nil {
+		code, ok := currency.NormalizeCode(*input.Currency)
+		if !ok {
+			return nil, errors.New("invalid currency")
+		}
+		recurring.Currency = code
+	}
+	wasActive := recurring.Active
+	if input.Active != nil {
+		recurring.Active = *input.Active
+	}
+	resumed := !wasActive && recurring.Active
+
+	var lastPosted *time.Time
+	if recurring.Occurrences > 0 {
+		last := recurring.OccurrenceDate(recurring.Occurrences - 1)
+		lastPosted = &last
+	}
+
+	scheduleChanged := false
+	if input.Frequency != nil && *input.Frequency != recurring.Frequency {
+		recurring.Frequency = *input.Frequency
+		scheduleChanged = true
+	}
+	if input.Interval != nil && *input.Interval != recurring.Interval {
+		recurring.Interval = *input.Interval
+		scheduleChanged = true
+	}
+	if input.StartDate != nil && !input.StartDate.Equal(recurring.StartDate) {
+		recurring.StartDate = *input.StartDate
+		scheduleChanged = true
+	}
+	if input.ClearEnd {
+		recurring.EndDate = nil
+	} else if input.EndDate != nil {
+		recurring.EndDate = input.EndDate
+	}
+	if recurring.EndDate != nil && recurring.EndDate.Before(recurring.StartDate) {
+		return nil, errors.New("end_date must be after start_date")
+	}
+
+	if scheduleChanged || resumed {
+		recurring.Occurrences = resumeIndex(&recurring, lastPosted, time.Now(), resumed)
+	}
+	recurring.NextRunDate = recurring.NextOccurrence()
+
+	if err := s.db.Omit(clause.Associations).Save(&recurring).Error; err != nil {
+		return nil, err
+	}
+
+	// Load category relationship
+	s.db.Preload("Category").First(&recurring, recurring.ID)
+
+	return &recurring, nil
+}
+
+// Delete soft deletes a recurring expense. Expenses already posted are kept.
+func (s *Service) Delete(id, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.RecurringExpense{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("recurring expense not found")
+	}
+	return nil
+}
+
+// MaterializeDue posts every occurrence that is due at the given time and
+// returns the number of expenses created. Each occurrence is written with a
+// deterministic external ID, so running this again (or concurrently, or
+// after a crash between posting and advancing the schedule) never posts
+// the same occurrence twice. A schedule that fails is logged and skipped,
+// so it doesn't hold up the others; the error reports how many failed.
+func (s *Service) MaterializeDue(now time.Time) (int, error) {
+	var dueIDs []uint
+	err := s.db.Model(&models.RecurringExpense{}).
+		Where("active = ? AND next_run_date IS NOT NULL AND next_run_date <= ?", true, now).
+		Pluck("id", &dueIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	created, failed := 0, 0
+	for _, id := range dueIDs {
+		count, err := s.materialize(id, now)
+		if err != nil {
+			log.Printf("recurring: failed to post recurring expense %d: %v", id, err)
+			failed++
+			continue
+		}
+		created += count
+	}
+
+	if failed > 0 {
+		return created, fmt.Errorf("%d of %d due recurring expense(s) failed", failed, len(dueIDs))
+	}
+	return created, nil
+}
+
+// materialize posts the due occurrences of a single recurring expense
+func (s *Service) materialize(id uint, now time.Time) (int, error) {
+	created := 0
+	var userID uint
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the schedule so concurrent runs skip it instead of racing
+		var recurring models.RecurringExpense
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND active = ?", id, true).
+			First(&recurring).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		userID = recurring.UserID
+
+		for i := 0; i < maxCatchUp; i++ {
+			next := recurring.NextOccurrence()
+			if next == nil || next.After(now) {
+				break
+			}
+
+			externalID := fmt.Sprintf("recurring:%d:%s", recurring.ID, next.Format("2006-01-02"))
+			expense := &models.Expense{
+				Name:        recurring.Name,
+				CategoryID:  recurring.CategoryID,
+				UserID:      recurring.UserID,
+				Unit:        recurring.Unit,
+				PerUnitCost: recurring.PerUnitCost,
+				Currency:    recurring.Currency,
+				ExpenseDate: *next,
+				ExternalID:  &externalID,
+			}
+
+			// Total is calculated automatically in BeforeSave hook
+			result := tx.Omit(clause.Associations).
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(expense)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				// Posted by the scheduler, so there is no actor
+				if err := revision.Record(tx, models.RevisionCreate, nil, nil, expense); err != nil {
+					return err
+				}
+				created++
+			}
+
+			recurring.Occurrences++
+		}
+
+		recurring.NextRunDate = recurring.NextOccurrence()
+		return tx.Model(&recurring).Updates(map[string]interface{}{
+			"occurrences":   recurring.Occurrences,
+			"next_run_date": recurring.NextRunDate,
+		}).Error
+	})
+
+	if err == nil && created > 0 {
+		for _, fn := range s.onCreate {
+			fn(userID)
+		}
+	}
+
+	return created, err
+}
+
+// resumeIndex returns the index of the first occurrence to post after a
+// schedule changed or was resumed: the first one after lastPosted (if
+// anything was posted) and not before the day of now. A changed schedule
+// that never posted anything starts over from its start date; a resumed
+// one skips everything missed while it was paused.
+func resumeIndex(recurring *models.RecurringExpense, lastPosted *time.Time, now time.Time, resumed bool) int {
+	if lastPosted == nil && !resumed {
+		return 0
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	n := 0
+	for {
+		date := recurring.OccurrenceDate(n)
+		if (lastPosted == nil || date.After(*lastPosted)) && !date.Before(today) {
+			return n
+		}
+		n++
+	}
+}
+
+// resolveCurrency validates a currency code, defaulting to the user's base currency
+func (s *Service) resolveCurrency(userID uint, code string) (string, error) {
+	if code == "" {
+		var user models.User
+		if err := s.db.Select("base_currency").First(&user, userID).Error; err != nil {
+			return "", err
+		}
+		if user.BaseCurrency == "" {
+			return models.DefaultCurrency, nil
+		}
+		return user.BaseCurrency, nil
+	}
+	code, ok := currency.NormalizeCode(code)
+	if !ok {
+		return "", errors.New("invalid currency")
+	}
+	return code, nil
+}
+
+// verifyCategory checks that a category exists and the user may use it,
+// i.e. it is a default category or one of their own
+func (s *Service) verifyCategory(userID, categoryID uint) error {
+	var category models.Category
+	err := s.db.Where("id = ? AND (user_id IS NULL OR user_id = ?)", categoryID, userID).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found")
+		}
+		return err
+	}
+	return nil
+}
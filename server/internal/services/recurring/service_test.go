@@ -0,0 +1,78 @@
+package recurring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+)
+
+func TestResumeIndex(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	now := time.Date(2026, 5, 20, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		frequency  string
+		start      time.Time
+		lastPosted *time.Time
+		want       int
+	}{
+		{"nothing posted starts over", models.FrequencyMonthly, day(1, 1), nil, 0},
+		{"past periods are skipped", models.FrequencyMonthly, day(1, 15), ptr(day(5, 1)), 5},                                 // Jun 15
+		{"monthly to weekly resumes today", models.FrequencyWeekly, day(5, 6), ptr(day(5, 1)), 2},                            // May 20
+		{"start moved earlier", models.FrequencyMonthly, day(1, 1), ptr(day(5, 10)), 5},                                      // Jun 1
+		{"start moved into the future", models.FrequencyMonthly, day(7, 1), ptr(day(5, 1)), 0},                               // Jul 1
+		{"last posted after today", models.FrequencyDaily, day(5, 1), ptr(time.Date(2026, 5, 22, 0, 0, 0, 0, time.UTC)), 22}, // May 23
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &models.RecurringExpense{Frequency: tt.frequency, Interval: 1, StartDate: tt.start}
+			if got := resumeIndex(r, tt.lastPosted, now, false); got != tt.want {
+				t.Errorf("resumeIndex() = %d (%v), want %d (%v)", got, r.OccurrenceDate(got), tt.want, r.OccurrenceDate(tt.want))
+			}
+		})
+	}
+}
+
+func TestResumeIndexAfterPause(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	now := time.Date(2026, 5, 20, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		frequency  string
+		start      time.Time
+		lastPosted *time.Time
+		want       time.Time
+	}{
+		// Posted up to Mar 1, paused, resumed on May 20
+		{"daily", models.FrequencyDaily, day(1, 1), ptr(day(3, 1)), day(5, 20)},
+		{"weekly", models.FrequencyWeekly, day(1, 7), ptr(day(3, 4)), day(5, 20)},
+		{"monthly", models.FrequencyMonthly, day(1, 25), ptr(day(2, 25)), day(5, 25)},
+		// Paused before anything was posted
+		{"never posted", models.FrequencyMonthly, day(1, 10), nil, day(6, 10)},
+		// Paused and resumed before the next occurrence was due
+		{"nothing missed", models.FrequencyMonthly, day(1, 25), ptr(day(4, 25)), day(5, 25)},
+		{"start in the future", models.FrequencyMonthly, day(8, 1), nil, day(8, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &models.RecurringExpense{Frequency: tt.frequency, Interval: 1, StartDate: tt.start}
+			got := r.OccurrenceDate(resumeIndex(r, tt.lastPosted, now, true))
+			if !got.Equal(tt.want) {
+				t.Errorf("resumed at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,180 @@
+package revision
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"gorm.io/gorm"
+)
+
+// Service handles the expense change history
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new revision service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// Snapshot is the recorded state of an expense
+type Snapshot struct {
+	Name        string       `json:"name"`
+	CategoryID  uint         `json:"category_id"`
+	Unit        float64      `json:"unit"`
+	PerUnitCost money.Amount `json:"per_unit_cost"`
+	Total       money.Amount `json:"total"`
+	Currency    string       `json:"currency"`
+	ExpenseDate time.Time    `json:"expense_date"`
+	ExternalID  *string      `json:"external_id,omitempty"`
+	AccountID   *uint        `json:"account_id,omitempty"`
+	MerchantID  *uint        `json:"merchant_id,omitempty"`
+	RawMerchant *string      `json:"raw_merchant,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
+	Tags        []string     `json:"tags"`
+}
+
+// Query filters revisions across users (admin only). Zero values match everything.
+type Query struct {
+	UserID    uint
+	ExpenseID uint
+	ActorID   uint
+	Action    string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// ValidAction reports whether the action is a revision action
+func ValidAction(action string) bool {
+	switch action {
+	case models.RevisionCreate, models.RevisionUpdate, models.RevisionDelete, models.RevisionRestore, models.RevisionPurge:
+		return true
+	}
+	return false
+}
+
+// Record writes a revision for a change to an expense. before is nil for
+// creations and after is nil for deletions; actorID is nil for changes made
+// by the system. Pass the transaction of the change so the revision is only
+// kept if the change is.
+func Record(db *gorm.DB, action string, actorID *uint, before, after *models.Expense) error {
+	current := after
+	if current == nil {
+		current = before
+	}
+	if current == nil {
+		return errors.New("revision needs a before or after state")
+	}
+
+	revision := &models.ExpenseRevision{
+		ExpenseID: current.ID,
+		UserID:    current.UserID,
+		ActorID:   actorID,
+		Action:    action,
+	}
+
+	var err error
+	if revision.Before, err = snapshot(before); err != nil {
+		return err
+	}
+	if revision.After, err = snapshot(after); err != nil {
+		return err
+	}
+
+	return db.Create(revision).Error
+}
+
+// snapshot encodes the recorded fields of an expense
+func snapshot(expense *models.Expense) (models.JSON, error) {
+	if expense == nil {
+		return nil, nil
+	}
+
+	tags := make([]string, len(expense.Tags))
+	for i, tag := range expense.Tags {
+		tags[i] = tag.Name
+	}
+
+	return json.Marshal(Snapshot{
+		Name:        expense.Name,
+		CategoryID:  expense.CategoryID,
+		Unit:        expense.Unit,
+		PerUnitCost: expense.PerUnitCost,
+		Total:       expense.Total,
+		Currency:    expense.Currency,
+		ExpenseDate: expense.ExpenseDate,
+		ExternalID:  expense.ExternalID,
+		AccountID:   expense.AccountID,
+		MerchantID:  expense.MerchantID,
+		RawMerchant: expense.RawMerchant,
+		Notes:       expense.Notes,
+		Tags:        tags,
+	})
+}
+
+// GetByExpense retrieves the history of one of the user's expenses, oldest
+// first. It also works for expenses in the trash or already purged.
+func (s *Service) GetByExpense(expenseID, userID uint) ([]models.ExpenseRevision, error) {
+	var revisions []models.ExpenseRevision
+
+	err := s.db.
+		Where("expense_id = ? AND user_id = ?", expenseID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&revisions).Error
+
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, errors.New("expense not found")
+	}
+
+	return revisions, nil
+}
+
+// Search retrieves revisions across users, newest first (admin only)
+func (s *Service) Search(query Query, limit, offset int) ([]models.ExpenseRevision, int64, error) {
+	var revisions []models.ExpenseRevision
+	var total int64
+
+	db := s.db.Model(&models.ExpenseRevision{})
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.ExpenseID != 0 {
+		db = db.Where("expense_id = ?", query.ExpenseID)
+	}
+	if query.ActorID != 0 {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.StartDate != nil {
+		db = db.Where("created_at >= ?", *query.StartDate)
+	}
+	if query.EndDate != nil {
+		db = db.Where("created_at <= ?", *query.EndDate)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
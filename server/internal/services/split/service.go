@@ -0,0 +1,558 @@
+package split
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service handles shared expenses, balances and settle-ups
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new split service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// ParticipantInput identifies a participant by user ID or email; it must be
+// the user or one of their contacts. Percentage (at most two decimals) is
+// used by percentage splits and Amount by exact splits.
+type ParticipantInput struct {
+	UserID     uint         `json:"user_id"`
+	Email      string       `json:"email"`
+	Percentage float64      `json:"percentage"`
+	Amount     money.Amount `json:"amount"`
+}
+
+// SplitInput represents the input for sharing an expense
+type SplitInput struct {
+	Method       string             `json:"method" validate:"required"` // equal, percentage or exact
+	Participants []ParticipantInput `json:"participants" validate:"required"`
+}
+
+// SettlementInput represents the input for recording a settle-up payment
+// from the current user to one of their contacts
+type SettlementInput struct {
+	ToUserID uint         `json:"to_user_id"`
+	ToEmail  string       `json:"to_email"`
+	Amount   money.Amount `json:"amount" validate:"required,gt=0"`
+	Currency string       `json:"currency"` // defaults to the user's base currency
+	Date     time.Time    `json:"date"`
+	Note     string       `json:"note"`
+}
+
+// Balance is the net amount between the current user and another user in
+// one currency. A positive Amount means the other user owes the current user.
+type Balance struct {
+	UserID   uint         `json:"user_id"`
+	Name     string       `json:"name"`
+	Email    string       `json:"email"`
+	Currency string       `json:"currency"`
+	Amount   money.Amount `json:"amount"`
+}
+
+// ContactEntry is a contact of the user, or a request from another user to
+// become one that awaits the user's answer
+type ContactEntry struct {
+	ID     uint   `json:"id"`      // ID of the contact record, to accept or remove it
+	UserID uint   `json:"user_id"` // the other user
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Status string `json:"status"` // accepted, or pending for an incoming request
+}
+
+// ValidMethod reports whether the split method is supported
+func ValidMethod(method string) bool {
+	switch method {
+	case models.SplitEqual, models.SplitPercentage, models.SplitExact:
+		return true
+	}
+	return false
+}
+
+// Share splits an expense of the user among the participants, replacing any
+// previous split. The user paid for the expense; participants who are not
+// the payer owe their share to them. Only the user's contacts can be
+// participants.
+func (s *Service) Share(expenseID, userID uint, input SplitInput) (*models.Expense, error) {
+	if !ValidMethod(input.Method) {
+		return nil, errors.New("invalid split method")
+	}
+	if len(input.Participants) == 0 {
+		return nil, errors.New("split needs at least one other participant")
+	}
+
+	var expense models.Expense
+	if err := s.db.Where("id = ? AND user_id = ?", expenseID, userID).First(&expense).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("expense not found")
+		}
+		return nil, err
+	}
+
+	splits := make([]models.ExpenseSplit, len(input.Participants))
+	seen := make(map[uint]bool, len(input.Participants))
+	hasOther := false
+	for i, participant := range input.Participants {
+		participantID, err := s.resolveContact(userID, participant.UserID, participant.Email)
+		if err != nil {
+			return nil, err
+		}
+		if seen[participantID] {
+			return nil, errors.New("duplicate participant")
+		}
+		seen[participantID] = true
+		if participantID != userID {
+			hasOther = true
+		}
+
+		splits[i] = models.ExpenseSplit{
+			ExpenseID: expense.ID,
+			UserID:    participantID,
+			Method:    input.Method,
+		}
+		switch input.Method {
+		case models.SplitEqual:
+			splits[i].Shares = 1
+		case models.SplitPercentage:
+			if participant.Percentage <= 0 {
+				return nil, errors.New("percentages must be positive")
+			}
+			hundredths, ok := percentHundredths(participant.Percentage)
+			if !ok {
+				return nil, errors.New("percentages can have at most two decimals")
+			}
+			splits[i].Shares = hundredths
+		case models.SplitExact:
+			if participant.Amount <= 0 {
+				return nil, errors.New("split amounts must be positive")
+			}
+			splits[i].Shares = participant.Amount.Minor()
+		}
+	}
+	if !hasOther {
+		return nil, errors.New("split needs at least one other participant")
+	}
+
+	switch input.Method {
+	case models.SplitPercentage:
+		var sum int64
+		for _, split := range splits {
+			sum += split.Shares
+		}
+		if sum != 100*100 {
+			return nil, errors.New("percentages must add up to 100")
+		}
+	case models.SplitExact:
+		var sum money.Amount
+		for _, participant := range input.Participants {
+			sum += participant.Amount
+		}
+		if sum != expense.Total {
+			return nil, errors.New("split amounts must add up to the expense total")
+		}
+	}
+	allocate(expense.Total, splits)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&splits).Error; err != nil {
+			return err
+		}
+		return touchExpense(tx, expense.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getExpense(expense.ID)
+}
+
+// Unshare removes the split of an expense of the user
+func (s *Service) Unshare(expenseID, userID uint) error {
+	var count int64
+	s.db.Model(&models.Expense{}).Where("id = ? AND user_id = ?", expenseID, userID).Count(&count)
+	if count == 0 {
+		return errors.New("expense not found")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expense_id = ?", expenseID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		return touchExpense(tx, expenseID)
+	})
+}
+
+// touchExpense bumps the updated_at of an expense whose splits changed, so
+// its ETag changes with them
+func touchExpense(tx *gorm.DB, expenseID uint) error {
+	return tx.Model(&models.Expense{}).Where("id = ?", expenseID).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// Rebalance recalculates the split amounts of an expense after its total
+// changed, keeping each participant's shares. It does nothing for expenses
+// that are not shared. Pass a transaction to make it part of the update.
+func Rebalance(db *gorm.DB, expense *models.Expense) error {
+	var splits []models.ExpenseSplit
+	if err := db.Where("expense_id = ?", expense.ID).Order("id ASC").Find(&splits).Error; err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+
+	allocate(expense.Total, splits)
+	for _, split := range splits {
+		if err := db.Model(&split).Update("amount", split.Amount).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetShared lists the expenses the user paid for and shared, or has a share in
+func (s *Service) GetShared(userID uint) ([]models.Expense, error) {
+	var expenses []models.Expense
+
+	err := s.db.
+		Preload("Category").
+		Preload("User").
+		Preload("Splits", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Splits.User").
+		Where("EXISTS (SELECT 1 FROM expense_splits WHERE expense_splits.expense_id = expenses.id AND (expenses.user_id = ? OR expense_splits.user_id = ?))", userID, userID).
+		Order("expense_date DESC, id DESC").
+		Find(&expenses).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return expenses, nil
+}
+
+// GetBalances returns the net balance between the user and everyone they
+// share expenses with, one entry per user and currency. Debts in both
+// directions are netted, so each pair of users has a single amount per
+// currency; settled pairs are left out.
+func (s *Service) GetBalances(userID uint) ([]Balance, error) {
+	balances := []Balance{}
+
+	err := s.db.Raw(`
+		SELECT t.other_id AS user_id, users.name, users.email, t.currency, SUM(t.amount) AS amount
+		FROM (
+			SELECT expense_splits.user_id AS other_id, expenses.currency, expense_splits.amount
+			FROM expense_splits JOIN expenses ON expenses.id = expense_splits.expense_id AND expenses.deleted_at IS NULL
+			WHERE expenses.user_id = @user AND expense_splits.user_id <> @user
+			UNION ALL
+			SELECT expenses.user_id, expenses.currency, -expense_splits.amount
+			FROM expense_splits JOIN expenses ON expenses.id = expense_splits.expense_id AND expenses.deleted_at IS NULL
+			WHERE expense_splits.user_id = @user AND expenses.user_id <> @user
+			UNION ALL
+			SELECT to_user_id, currency, amount FROM settlements WHERE from_user_id = @user
+			UNION ALL
+			SELECT from_user_id, currency, -amount FROM settlements WHERE to_user_id = @user
+		) t
+		JOIN users ON users.id = t.other_id
+		GROUP BY t.other_id, users.name, users.email, t.currency
+		HAVING SUM(t.amount) <> 0
+		ORDER BY users.name, t.currency`,
+		map[string]interface{}{"user": userID},
+	).Scan(&balances).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// CreateSettlement records a payment from the user to one of their contacts
+func (s *Service) CreateSettlement(userID uint, input SettlementInput) (*models.Settlement, error) {
+	toUserID, err := s.resolveContact(userID, input.ToUserID, input.ToEmail)
+	if err != nil {
+		return nil, err
+	}
+	if toUserID == userID {
+		return nil, errors.New("cannot settle with yourself")
+	}
+	if input.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	currencyCode := input.Currency
+	if currencyCode == "" {
+		var user models.User
+		if err := s.db.Select("base_currency").First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		currencyCode = user.BaseCurrency
+		if currencyCode == "" {
+			currencyCode = models.DefaultCurrency
+		}
+	}
+	currencyCode, ok := currency.NormalizeCode(currencyCode)
+	if !ok {
+		return nil, errors.New("invalid currency")
+	}
+
+	if input.Date.IsZero() {
+		input.Date = time.Now()
+	}
+
+	settlement := &models.Settlement{
+		FromUserID: userID,
+		ToUserID:   toUserID,
+		Amount:     input.Amount,
+		Currency:   currencyCode,
+		Date:       input.Date,
+		Note:       input.Note,
+	}
+	if err := s.db.Create(settlement).Error; err != nil {
+		return nil, err
+	}
+
+	// Load user relationships
+	s.db.Preload("FromUser").Preload("ToUser").First(settlement, settlement.ID)
+
+	return settlement, nil
+}
+
+// GetSettlements lists the settle-up payments the user made or received
+func (s *Service) GetSettlements(userID uint) ([]models.Settlement, error) {
+	var settlements []models.Settlement
+
+	err := s.db.
+		Preload("FromUser").
+		Preload("ToUser").
+		Where("from_user_id = ? OR to_user_id = ?", userID, userID).
+		Order("date DESC, id DESC").
+		Find(&settlements).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return settlements, nil
+}
+
+// DeleteSettlement deletes a settle-up payment recorded by the user
+func (s *Service) DeleteSettlement(id, userID uint) error {
+	result := s.db.Where("id = ? AND from_user_id = ?", id, userID).Delete(&models.Settlement{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("settlement not found")
+	}
+	return nil
+}
+
+// getExpense loads an expense with its split
+func (s *Service) getExpense(id uint) (*models.Expense, error) {
+	var expense models.Expense
+	err := s.db.
+		Preload("Category").
+		Preload("Splits", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Splits.User").
+		First(&expense, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// RequestContact asks the user with the given email to become a contact of
+// the user. To keep emails from being probed, it succeeds the same way
+// whether or not anyone has that email, and outgoing requests are never
+// listed. Asking someone who already asked the user accepts their request.
+func (s *Service) RequestContact(userID uint, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	var other models.User
+	err := s.db.Select("id").Where("LOWER(email) = LOWER(?)", email).First(&other).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if other.ID == userID {
+		return errors.New("cannot add yourself as a contact")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Contact
+		err := tx.Where("(user_id = ? AND contact_id = ?) OR (user_id = ? AND contact_id = ?)", userID, other.ID, other.ID, userID).
+			First(&existing).Error
+		if err == nil {
+			if existing.UserID == other.ID && existing.Status == models.ContactPending {
+				return tx.Model(&existing).Update("status", models.ContactAccepted).Error
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Contact{
+			UserID:    userID,
+			ContactID: other.ID,
+			Status:    models.ContactPending,
+		}).Error
+	})
+}
+
+// GetContacts lists the user's contacts and the requests awaiting their answer
+func (s *Service) GetContacts(userID uint) ([]ContactEntry, error) {
+	contacts := []ContactEntry{}
+
+	err := s.db.Raw(`
+		SELECT contacts.id, users.id AS user_id, users.name, users.email, contacts.status
+		FROM contacts
+		JOIN users ON users.deleted_at IS NULL AND users.id = CASE WHEN contacts.user_id = @user THEN contacts.contact_id ELSE contacts.user_id END
+		WHERE (contacts.status = @accepted AND (contacts.user_id = @user OR contacts.contact_id = @user))
+			OR (contacts.status = @pending AND contacts.contact_id = @user)
+		ORDER BY contacts.status, users.name`,
+		map[string]interface{}{"user": userID, "accepted": models.ContactAccepted, "pending": models.ContactPending},
+	).Scan(&contacts).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return contacts, nil
+}
+
+// AcceptContact accepts a contact request sent to the user
+func (s *Service) AcceptContact(id, userID uint) error {
+	result := s.db.Model(&models.Contact{}).
+		Where("id = ? AND contact_id = ? AND status = ?", id, userID, models.ContactPending).
+		Update("status", models.ContactAccepted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("contact not found")
+	}
+	return nil
+}
+
+// DeleteContact removes a contact of the user or declines a request sent to
+// them. Expenses already shared with the contact are kept.
+func (s *Service) DeleteContact(id, userID uint) error {
+	result := s.db.Where("id = ? AND (user_id = ? OR contact_id = ?)", id, userID, userID).Delete(&models.Contact{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("contact not found")
+	}
+	return nil
+}
+
+// resolveContact finds a participant by ID or, if no ID is given, by email.
+// It must be the user or one of their accepted contacts; unknown users and
+// users who aren't contacts get the same error, so it can't be used to
+// find out who has an account.
+func (s *Service) resolveContact(userID, id uint, email string) (uint, error) {
+	var user models.User
+	query := s.db.Select("id")
+	if id != 0 {
+		query = query.Where("id = ?", id)
+	} else if email = strings.TrimSpace(email); email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", email)
+	} else {
+		return 0, errors.New("participant needs a user_id or email")
+	}
+
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("user is not one of your contacts")
+		}
+		return 0, err
+	}
+	if user.ID == userID {
+		return user.ID, nil
+	}
+
+	var count int64
+	err := s.db.Model(&models.Contact{}).
+		Where("status = ? AND ((user_id = ? AND contact_id = ?) OR (user_id = ? AND contact_id = ?))", models.ContactAccepted, userID, user.ID, user.ID, userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, errors.New("user is not one of your contacts")
+	}
+	return user.ID, nil
+}
+
+// percentHundredths converts a percentage with at most two decimals into
+// hundredths of a percent
+func percentHundredths(percentage float64) (int64, bool) {
+	hundredths, err := money.FromFloat(percentage)
+	if err != nil || hundredths.Float64() != percentage {
+		return 0, false
+	}
+	return hundredths.Minor(), true
+}
+
+// allocate divides total among the splits in proportion to their shares,
+// in exact integer minor units. Each split gets its share rounded toward
+// zero, and the cents left over go one each to the largest remainders,
+// earlier splits first on ties, so the amounts always add up to total.
+func allocate(total money.Amount, splits []models.ExpenseSplit) {
+	var sumShares int64
+	for _, split := range splits {
+		sumShares += split.Shares
+	}
+	if sumShares <= 0 {
+		return
+	}
+
+	sign, minor := int64(1), total.Minor()
+	if minor < 0 {
+		sign, minor = -1, -minor
+	}
+
+	remainders := make([]*big.Int, len(splits))
+	allocated := int64(0)
+	for i := range splits {
+		exact := new(big.Int).Mul(big.NewInt(minor), big.NewInt(splits[i].Shares))
+		share, remainder := new(big.Int).QuoRem(exact, big.NewInt(sumShares), new(big.Int))
+		splits[i].Amount = money.FromMinor(sign * share.Int64())
+		remainders[i] = remainder
+		allocated += share.Int64()
+	}
+
+	order := make([]int, len(splits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].Cmp(remainders[order[b]]) > 0
+	})
+	for i := 0; allocated < minor; i++ {
+		splits[order[i%len(order)]].Amount += money.FromMinor(sign)
+		allocated++
+	}
+}
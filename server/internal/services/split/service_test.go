@@ -0,0 +1,86 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  money.Amount
+		shares []int64
+		want   []money.Amount
+	}{
+		{"equal, even", 900, []int64{1, 1, 1}, []money.Amount{300, 300, 300}},
+		// 10.00 / 3: one cent left over, the first split gets it
+		{"equal, remainder to first", 1000, []int64{1, 1, 1}, []money.Amount{334, 333, 333}},
+		{"equal, two cents left", 1100, []int64{1, 1, 1}, []money.Amount{367, 367, 366}},
+		// 33.33% / 33.33% / 33.34% of 1.00
+		{"percentage", 100, []int64{3333, 3333, 3334}, []money.Amount{33, 33, 34}},
+		// 0.01 at 50/50 goes to the first participant only
+		{"one cent", 1, []int64{5000, 5000}, []money.Amount{1, 0}},
+		// Largest remainder wins over position
+		{"largest remainder", 1000, []int64{1000, 2500, 6500}, []money.Amount{100, 250, 650}},
+		{"largest remainder, uneven", 1001, []int64{1500, 1500, 7000}, []money.Amount{150, 150, 701}},
+		// Rebalanced exact split keeps the proportions
+		{"exact rescaled", 2000, []int64{250, 750}, []money.Amount{500, 1500}},
+		{"negative total", -1000, []int64{1, 1, 1}, []money.Amount{-334, -333, -333}},
+		{"large total", 9_000_000_000_000_000, []int64{3333, 3333, 3334}, []money.Amount{2_999_700_000_000_000, 2_999_700_000_000_000, 3_000_600_000_000_000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits := make([]models.ExpenseSplit, len(tt.shares))
+			for i, shares := range tt.shares {
+				splits[i].Shares = shares
+			}
+
+			allocate(tt.total, splits)
+
+			var sum money.Amount
+			for i, split := range splits {
+				sum += split.Amount
+				if split.Amount != tt.want[i] {
+					t.Errorf("split %d = %s, want %s", i, split.Amount, tt.want[i])
+				}
+			}
+			if sum != tt.total {
+				t.Errorf("splits add up to %s, want %s", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestAllocateNoShares(t *testing.T) {
+	splits := []models.ExpenseSplit{{Amount: 5}, {Amount: 7}}
+	allocate(1000, splits)
+	if splits[0].Amount != 5 || splits[1].Amount != 7 {
+		t.Errorf("splits without shares changed: %+v", splits)
+	}
+}
+
+func TestPercentHundredths(t *testing.T) {
+	tests := []struct {
+		in     float64
+		want   int64
+		wantOK bool
+	}{
+		{50, 5000, true},
+		{33.33, 3333, true},
+		{0.01, 1, true},
+		{12.5, 1250, true},
+		{100, 10000, true},
+		{33.333, 0, false},
+		{0.001, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := percentHundredths(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("percentHundredths(%v) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package tag
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxNameLength is the longest allowed tag name
+const MaxNameLength = 50
+
+// Service handles tag business logic
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new tag service instance
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// TagInput represents the input for creating or renaming a tag
+type TagInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// TagSummary is a tag with the number of expenses carrying it
+type TagSummary struct {
+	models.Tag
+	ExpenseCount int64 `json:"expense_count"`
+}
+
+// NormalizeName trims, lower-cases and collapses whitespace in a tag name
+// and reports whether the result is a valid name
+func NormalizeName(name string) (string, bool) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if name == "" || len([]rune(name)) > MaxNameLength || strings.Contains(name, ",") {
+		return name, false
+	}
+	return name, true
+}
+
+// NormalizeNames normalizes a list of tag names, dropping duplicates
+func NormalizeNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name, ok := NormalizeName(name)
+		if !ok {
+			return nil, errors.New("invalid tag name")
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that don't exist yet. Pass a transaction to make it part of a larger
+// write.
+func FindOrCreate(db *gorm.DB, userID uint, names []string) ([]models.Tag, error) {
+	names, err := NormalizeNames(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name, UserID: userID}
+	}
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoNothing: true,
+	}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows that already existed were not returned by the insert
+	var found []models.Tag
+	if err := db.Where("user_id = ? AND name IN ?", userID, names).Order("name ASC").Find(&found).Error; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// GetByUser retrieves all tags of a user with their usage counts
+func (s *Service) GetByUser(userID uint) ([]TagSummary, error) {
+	var tags []TagSummary
+
+	err := s.db.Model(&models.Tag{}).
+		Select("tags.*, COUNT(expenses.id) as expense_count").
+		Joins("LEFT JOIN expense_tags ON expense_tags.tag_id = tags.id").
+		Joins("LEFT JOIN expenses ON expenses.id = expense_tags.expense_id AND expenses.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name ASC").
+		Scan(&tags).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Create creates a new tag
+func (s *Service) Create(userID uint, input TagInput) (*models.Tag, error) {
+	name, ok := NormalizeName(input.Name)
+	if !ok {
+		return nil, errors.New("invalid tag name")
+	}
+
+	var count int64
+	s.db.Model(&models.Tag{}).Where("user_id = ? AND name = ?", userID, name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("tag already exists")
+	}
+
+	tag := &models.Tag{Name: name, UserID: userID}
+	if err := s.db.Create(tag).Error; err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Update renames a tag. Expenses carrying the tag keep it.
+func (s *Service) Update(id, userID uint, input TagInput) (*models.Tag, error) {
+	name, ok := NormalizeName(input.Name)
+	if !ok {
+		return nil, errors.New("invalid tag name")
+	}
+
+	var tag models.Tag
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+
+	var count int64
+	s.db.Model(&models.Tag{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, id).Count(&count)
+	if count > 0 {
+		return nil, errors.New("tag already exists")
+	}
+
+	tag.Name = name
+	if err := s.db.Save(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Delete deletes a tag and removes it from every expense
+func (s *Service) Delete(id, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var tag models.Tag
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tag not found")
+			}
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM expense_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+}
@@ -0,0 +1,35 @@
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartPurger purges expired trash immediately and then on every tick of
+// the given interval, until the context is cancelled. It blocks, so run it
+// in its own goroutine.
+func (s *Service) StartPurger(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expenses, categories, err := s.PurgeExpired(ctx, time.Now())
+		if err != nil {
+			log.Println("Warning: Failed to purge trash:", err)
+		}
+		if expenses > 0 || categories > 0 {
+			log.Printf("Purged %d expense(s) and %d category(ies) from the trash", expenses, categories)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/services/receipt"
+	"github.com/parvejmia9/minflow/server/internal/services/revision"
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize limits how many expenses are purged per query by PurgeExpired
+const purgeBatchSize = 500
+
+// categoryReferences are the tables whose category_id has a foreign key to
+// categories, so a category they use can't be purged
+var categoryReferences = []string{"expenses", "recurring_expenses", "budgets"}
+
+// Service handles soft-deleted expenses and categories: listing, restoring
+// and permanently purging them
+type Service struct {
+	db        *gorm.DB
+	receipts  *receipt.Service
+	retention time.Duration
+	onRestore []func(userID uint)
+}
+
+// NewService creates a new trash service instance. Items are purged
+// automatically once they have been in the trash for longer than retention;
+// zero keeps them until they are purged by hand.
+func NewService(db *gorm.DB, receipts *receipt.Service, retention time.Duration) *Service {
+	return &Service{
+		db:        db,
+		receipts:  receipts,
+		retention: retention,
+	}
+}
+
+// OnRestore registers fn to be called after an expense of a user is restored
+func (s *Service) OnRestore(fn func(userID uint)) {
+	s.onRestore = append(s.onRestore, fn)
+}
+
+// TrashedExpense is an expense in the trash
+type TrashedExpense struct {
+	models.Expense
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at"` // nil when automatic purging is off
+}
+
+// TrashedCategory is a category in the trash
+type TrashedCategory struct {
+	models.Category
+	PurgeAt *time.Time `json:"purge_at"` // nil when automatic purging is off
+}
+
+// GetExpenses lists the user's expenses in the trash, most recently deleted first
+func (s *Service) GetExpenses(userID uint) ([]TrashedExpense, error) {
+	var expenses []models.Expense
+
+	err := s.db.Unscoped().
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Tags").
+		Preload("Merchant").
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id DESC").
+		Find(&expenses).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]TrashedExpense, len(expenses))
+	for i, expense := range expenses {
+		trashed[i] = TrashedExpense{
+			Expense:   expense,
+			DeletedAt: expense.DeletedAt.Time,
+			PurgeAt:   s.purgeAt(expense.DeletedAt.Time),
+		}
+	}
+
+	return trashed, nil
+}
+
+// RestoreExpense moves an expense out of the trash
+func (s *Service) RestoreExpense(id, userID uint) (*models.Expense, error) {
+	var expense models.Expense
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Expense{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("expense not found in trash")
+		}
+
+		err := tx.
+			Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Preload("Tags").
+			First(&expense, id).Error
+		if err != nil {
+			return err
+		}
+		return revision.Record(tx, models.RevisionRestore, &userID, nil, &expense)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fn := range s.onRestore {
+		fn(userID)
+	}
+
+	return &expense, nil
+}
+
+// PurgeExpense permanently deletes an expense in the trash, together with
+// its receipts, tags and split
+func (s *Service) PurgeExpense(ctx context.Context, id, userID uint) error {
+	var count int64
+	err := s.db.Unscoped().Model(&models.Expense{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("expense not found in trash")
+	}
+
+	return s.purgeExpenses(ctx, []uint{id}, &userID)
+}
+
+// EmptyExpenses permanently deletes every expense in the user's trash and
+// returns how many were purged
+func (s *Service) EmptyExpenses(ctx context.Context, userID uint) (int, error) {
+	var ids []uint
+	err := s.db.Unscoped().Model(&models.Expense{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.purgeExpenses(ctx, ids, &userID); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// GetCategories lists the user's categories in the trash, most recently deleted first
+func (s *Service) GetCategories(userID uint) ([]TrashedCategory, error) {
+	var categories []models.Category
+
+	err := s.db.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id DESC").
+		Find(&categories).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]TrashedCategory, len(categories))
+	for i, category := range categories {
+		trashed[i] = TrashedCategory{
+			Category: category,
+			PurgeAt:  s.purgeAt(category.DeletedAt.Time),
+		}
+	}
+
+	return trashed, nil
+}
+
+// RestoreCategory moves a user category out of the trash
+func (s *Service) RestoreCategory(id, userID uint) (*models.Category, error) {
+	result := s.db.Unscoped().Model(&models.Category{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("category not found in trash")
+	}
+
+	var category models.Category
+	if err := s.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// PurgeCategory permanently deletes a user category in the trash. Categories
+// still used by an expense (including expenses in the trash), a recurring
+// expense or a budget are kept.
+func (s *Service) PurgeCategory(id, userID uint) error {
+	var category models.Category
+	err := s.db.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found in trash")
+		}
+		return err
+	}
+
+	result := s.db.Unscoped().Scopes(unreferenced).Delete(&category)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("category is still in use")
+	}
+	return nil
+}
+
+// PurgeExpired permanently deletes expenses and categories that have been
+// in the trash for longer than the retention period. It returns the number
+// of expenses and categories purged.
+func (s *Service) PurgeExpired(ctx context.Context, now time.Time) (int, int, error) {
+	if s.retention <= 0 {
+		return 0, 0, nil
+	}
+	cutoff := now.Add(-s.retention)
+
+	expenses := 0
+	for {
+		var ids []uint
+		err := s.db.Unscoped().Model(&models.Expense{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Order("id ASC").
+			Limit(purgeBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return expenses, 0, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		if err := s.purgeExpenses(ctx, ids, nil); err != nil {
+			return expenses, 0, err
+		}
+		expenses += len(ids)
+	}
+
+	// Categories still in use are left alone
+	result := s.db.Unscoped().
+		Where("user_id IS NOT NULL AND deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Scopes(unreferenced).
+		Delete(&models.Category{})
+	if result.Error != nil {
+		return expenses, 0, result.Error
+	}
+
+	return expenses, int(result.RowsAffected), nil
+}
+
+// purgeExpenses permanently deletes expenses and everything attached to
+// them except their history. Receipt files are removed once the rows are
+// gone. actorID is nil for automatic purges.
+func (s *Service) purgeExpenses(ctx context.Context, ids []uint, actorID *uint) error {
+	var receipts []models.Receipt
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var expenses []models.Expense
+		if err := tx.Unscoped().Preload("Tags").Where("id IN ?", ids).Find(&expenses).Error; err != nil {
+			return err
+		}
+		for i := range expenses {
+			if err := revision.Record(tx, models.RevisionPurge, actorID, &expenses[i], nil); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("expense_id IN ?", ids).Find(&receipts).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id IN ?", ids).Delete(&models.Receipt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id IN ?", ids).Delete(&models.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM expense_tags WHERE expense_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Expense{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.receipts.DeleteFiles(ctx, receipts)
+	return nil
+}
+
+// unreferenced limits a category query to categories no expense, recurring
+// expense or budget uses. Soft-deleted rows count too, as their foreign keys
+// would still make the delete fail.
+func unreferenced(db *gorm.DB) *gorm.DB {
+	for _, table := range categoryReferences {
+		db = db.Where("NOT EXISTS (SELECT 1 FROM " + table + " WHERE " + table + ".category_id = categories.id)")
+	}
+	return db
+}
+
+// purgeAt returns when an item deleted at deletedAt will be purged
+func (s *Service) purgeAt(deletedAt time.Time) *time.Time {
+	if s.retention <= 0 {
+		return nil
+	}
+	at := deletedAt.Add(s.retention)
+	return &at
+}
@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parvejmia9/minflow/server/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds statements without a database connection
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+// A trashed category that a budget (or recurring expense) still uses must
+// not be part of the delete, or its foreign key fails the whole purge
+func TestPurgeSkipsCategoriesUsedByBudgets(t *testing.T) {
+	db := dryRunDB(t)
+	userID := uint(7)
+	category := models.Category{ID: 3, UserID: &userID}
+
+	tests := []struct {
+		name  string
+		query *gorm.DB
+	}{
+		{"purge one", db.Unscoped().Scopes(unreferenced).Delete(&category)},
+		{"purge expired", db.Unscoped().
+			Where("user_id IS NOT NULL AND deleted_at IS NOT NULL AND deleted_at < ?", time.Now()).
+			Scopes(unreferenced).
+			Delete(&models.Category{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.query.Error != nil {
+				t.Fatalf("Delete() error = %v", tt.query.Error)
+			}
+			sql := tt.query.Statement.SQL.String()
+			if !strings.HasPrefix(sql, `DELETE FROM "categories"`) {
+				t.Fatalf("SQL = %s, want a hard delete", sql)
+			}
+			for _, table := range []string{"expenses", "recurring_expenses", "budgets"} {
+				want := "NOT EXISTS (SELECT 1 FROM " + table + " WHERE " + table + ".category_id = categories.id)"
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL = %s\nmissing %s", sql, want)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 
 	"github.com/parvejmia9/minflow/server/internal/models"
+	"github.com/parvejmia9/minflow/server/internal/money"
+	"github.com/parvejmia9/minflow/server/internal/pagination"
+	"github.com/parvejmia9/minflow/server/internal/services/currency"
 	"gorm.io/gorm"
 )
 
@@ -41,6 +44,37 @@ func (s *Service) GetAll(limit, offset int) ([]models.User, int64, error) {
 	return users, total, nil
 }
 
+// GetAllAfter retrieves users using keyset pagination on (created_at, id).
+// It returns the cursor for the next page, or an empty string when there are
+// no more results.
+func (s *Service) GetAllAfter(limit int, cursor *pagination.Cursor) ([]models.User, string, error) {
+	var users []models.User
+
+	query := s.db.Model(&models.User{})
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit + 1).
+		Find(&users).Error
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(users) > limit {
+		users = users[:limit]
+		last := users[len(users)-1]
+		nextCursor = pagination.Cursor{Time: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return users, nextCursor, nil
+}
+
 // GetByID retrieves a single user by ID
 func (s *Service) GetByID(id uint) (*models.User, error) {
 	var user models.User
@@ -71,6 +105,24 @@ func (s *Service) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// UpdateBaseCurrency sets the currency a user's analytics are converted into
+func (s *Service) UpdateBaseCurrency(id uint, code string) (*models.User, error) {
+	code, ok := currency.NormalizeCode(code)
+	if !ok {
+		return nil, errors.New("invalid currency")
+	}
+
+	result := s.db.Model(&models.User{}).Where("id = ?", id).Update("base_currency", code)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	return s.GetByID(id)
+}
+
 // Delete soft deletes a user (admin only)
 func (s *Service) Delete(id uint) error {
 	// Don't allow deleting admin users
@@ -106,7 +158,7 @@ func (s *Service) GetUserStats(userID uint) (map[string]interface{}, error) {
 	stats["total_expenses"] = expenseCount
 
 	// Sum total spending
-	var totalSpending float64
+	var totalSpending money.Amount
 	s.db.Model(&models.Expense{}).
 		Where("user_id = ?", userID).
 		Select("COALESCE(SUM(total), 0)").